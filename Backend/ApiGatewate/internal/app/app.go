@@ -1,49 +1,139 @@
 package app
 
 import (
+	"crypto/rand"
 	"fmt"
 	"log/slog"
 
+	janitorapp "github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/app/janitor"
 	v1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/controller/rest/v1"
+	repository "github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/infra/postgres"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/identity"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/imaging"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/jwt"
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/s3"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage/local"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage/memory"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/tokencache"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/upload"
 
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/services"
 
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/pkg/httpserver"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/pkg/postgres"
 	"github.com/gin-gonic/gin"
 )
 
 type HttpServer struct {
-	s     *httpserver.Server
-	authS *services.AuthService
+	s        *httpserver.Server
+	internal *httpserver.Server
+	authS    *services.AuthService
+	db       *postgres.Postgres
+	images   *imaging.Processor
+	janitor  *janitorapp.App
 }
 
 func Run(
 	log *slog.Logger,
 	cfg *config.Config,
 ) *HttpServer {
+	// Caller identity forwarded to backend services
+	var signer *identity.Signer
+	switch {
+	case cfg.Identity.Secret != "":
+		signer = identity.NewSigner(cfg.Identity.Secret)
+	case cfg.Env == "local" || cfg.Env == "dev":
+		log.Warn("identity secret is empty, caller identity won't be forwarded")
+	default:
+		panic(fmt.Errorf("app - Run - identity secret is required outside local and dev"))
+	}
+
 	// Services
-	authService := services.NewAuthService(log, cfg.AuthServiceCfg)
+	authService := services.NewAuthService(log, cfg.AuthServiceCfg, signer)
 
 	// Clients
 	clients := v1.Clients{
 		Auth: authService.Connect(),
 	}
 
-	// S3
-	s3Storage := s3.NewS3Storage(log, cfg.S3)
+	// JWT verifier
+	var verifier *jwt.Verifier
+	if cfg.JWT.Enabled {
+		verifier = jwt.NewVerifier(log, cfg.JWT, clients.Auth)
+	}
+
+	// Access token cache
+	tokenCache := tokencache.New(log, cfg.TokenCache)
+
+	// Postgres
+	pg, err := postgres.New(cfg.Database.URL, postgres.MaxPoolSize(cfg.Database.PoolMax))
+	if err != nil {
+		panic(fmt.Errorf("app - Run - postgres.New: %w", err))
+	}
+
+	// Media
+	mediaStorage, err := newStorage(log, cfg.Storage, cfg.S3)
+	if err != nil {
+		panic(fmt.Errorf("app - Run - newStorage: %w", err))
+	}
+	mediaRepo := repository.NewMediaRepository(postgres.NewDBConnector(pg.Pool))
+	images := imaging.New(cfg.Images)
+	mediaService := services.NewMediaService(log, mediaRepo, mediaStorage, clients.Auth, upload.NewPolicies(cfg.Uploads), images, cfg.Media.PendingTTL)
+
+	// Janitor
+	janitor := janitorapp.New(log, mediaService, cfg.Media.CleanupInterval)
+	go janitor.Run()
 
 	// HTTP Server
 	handler := gin.New()
-	v1.NewRouter(handler, clients, log, s3Storage)
+	internalHandler := handler
+	if cfg.HTTP.InternalPort != "" {
+		internalHandler = gin.New()
+	}
+	v1.NewRouter(handler, internalHandler, clients, log, mediaService, mediaStorage, verifier, tokenCache)
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
 	log.Info("api gatewate server started", slog.String("addr", cfg.HTTP.Port))
 
+	// Internal routes listener
+	var internalServer *httpserver.Server
+	if cfg.HTTP.InternalPort != "" {
+		internalServer = httpserver.New(internalHandler, httpserver.Port(cfg.HTTP.InternalPort))
+		log.Info("internal server started", slog.String("addr", cfg.HTTP.InternalPort))
+	}
+
 	return &HttpServer{
-		s:     httpServer,
-		authS: authService,
+		s:        httpServer,
+		internal: internalServer,
+		authS:    authService,
+		db:       pg,
+		images:   images,
+		janitor:  janitor,
+	}
+}
+
+// newStorage returns the media storage selected by the driver
+func newStorage(log *slog.Logger, cfg config.StorageConfig, s3Cfg config.S3) (storage.Storage, error) {
+	switch cfg.Driver {
+	case "s3":
+		return s3.NewS3Storage(log, s3Cfg), nil
+	case "local", "memory":
+		secret := cfg.SignedURLs.Secret
+		if secret == "" {
+			log.Warn("storage signing secret is empty, signed urls won't survive a restart")
+			secret = rand.Text()
+		}
+		signer := storage.NewURLSigner(secret, cfg.SignedURLs.BaseURL)
+
+		if cfg.Driver == "memory" {
+			log.Warn("media is stored in memory and will be lost on restart")
+			return memory.New(signer, cfg.SignedURLs), nil
+		}
+		return local.New(log, cfg.Local, signer, cfg.SignedURLs)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
 	}
 }
 
@@ -53,8 +143,19 @@ func (s *HttpServer) Shutdown() {
 		slog.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err).Error())
 	}
 
+	if s.internal != nil {
+		err = s.internal.Shutdown()
+		if err != nil {
+			slog.Error(fmt.Errorf("app - Run - internalServer.Shutdown: %w", err).Error())
+		}
+	}
+
 	err = s.authS.CloseConn()
 	if err != nil {
 		slog.Error(fmt.Errorf("app - Run - httpServer.Shutdown - s.authS.CloseConn: %w", err).Error())
 	}
+
+	s.janitor.Stop()
+	s.images.Close()
+	s.db.Close()
 }
@@ -3,9 +3,11 @@ package common
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -66,6 +68,24 @@ func GetProtoErrWithStatusCode(err error) (int, error) {
 		case codes.Unauthenticated:
 			code = http.StatusUnauthorized
 			err = fmt.Errorf("Unauthorized: %s", st.Message())
+		case codes.PermissionDenied:
+			code = http.StatusForbidden
+			err = fmt.Errorf("Forbidden: %s", st.Message())
+		case codes.FailedPrecondition:
+			// Preconditions on the caller's own account are reported with a reason
+			if GetErrorReason(err) != "" {
+				code = http.StatusForbidden
+				err = fmt.Errorf("Forbidden: %s", st.Message())
+				break
+			}
+			code = http.StatusConflict
+			err = fmt.Errorf("Conflict: %s", st.Message())
+		case codes.Unimplemented:
+			code = http.StatusNotImplemented
+			err = fmt.Errorf("Not implemented: %s", st.Message())
+		case codes.ResourceExhausted:
+			code = http.StatusTooManyRequests
+			err = fmt.Errorf("Too many requests: %s", st.Message())
 		default:
 			code = http.StatusInternalServerError
 			err = fmt.Errorf("Unexpected error: %s", st.Message())
@@ -74,3 +94,36 @@ func GetProtoErrWithStatusCode(err error) (int, error) {
 
 	return code, err
 }
+
+// GetRetryAfter returns the retry delay attached to a proto error in whole seconds
+func GetRetryAfter(err error) (int, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, false
+	}
+
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+			delay := info.RetryDelay.AsDuration()
+			return int(math.Ceil(delay.Seconds())), delay > 0
+		}
+	}
+
+	return 0, false
+}
+
+// GetErrorReason returns the machine-readable reason attached to a proto error
+func GetErrorReason(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return ""
+	}
+
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+
+	return ""
+}
@@ -3,31 +3,126 @@ package config
 import (
 	"flag"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
-	Env            string            `yaml:"env" env-default:"local"`
-	HTTP           HTTPConfig        `yaml:"http"`
-	AuthServiceCfg AuthServiceConfig `yaml:"auth_service"`
-	S3             S3                `yaml:"s3"`
+	Env            string                  `yaml:"env" env-default:"local"`
+	HTTP           HTTPConfig              `yaml:"http"`
+	AuthServiceCfg AuthServiceConfig       `yaml:"auth_service"`
+	JWT            JWTConfig               `yaml:"jwt"`
+	TokenCache     TokenCacheConfig        `yaml:"token_cache"`
+	Identity       IdentityConfig          `yaml:"identity"`
+	Storage        StorageConfig           `yaml:"storage"`
+	S3             S3                      `yaml:"s3"`
+	Database       DatabaseConfig          `yaml:"database"`
+	Uploads        map[string]UploadPolicy `yaml:"uploads"`
+	Images         ImagesConfig            `yaml:"images"`
+	Media          MediaConfig             `yaml:"media"`
 	MigrationsPath string
 }
 
 type HTTPConfig struct {
 	Port string `yaml:"port" env-required:"true"`
+	// InternalPort serves the internal routes on a separate listener, they share Port when empty
+	InternalPort string `yaml:"internal_port"`
 }
 
 type AuthServiceConfig struct {
 	Addr string `yaml:"address" env:"AUTH_ADDRESS" env-required:"true"`
 }
 
+type JWTConfig struct {
+	Enabled             bool          `yaml:"enabled" env:"JWT_ENABLED" env-default:"false"`
+	Issuer              string        `yaml:"issuer" env-default:"psyhoapp-auth"`
+	RevocationCheck     bool          `yaml:"revocation_check" env-default:"true"`
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval" env-default:"5m"`
+}
+
+type TokenCacheConfig struct {
+	Enabled     bool          `yaml:"enabled" env:"TOKEN_CACHE_ENABLED" env-default:"true"`
+	Size        int           `yaml:"size" env-default:"10000"`
+	TTL         time.Duration `yaml:"ttl" env-default:"10s"`
+	NegativeTTL time.Duration `yaml:"negative_ttl" env-default:"2s"`
+}
+
+// IdentityConfig holds the secret shared with backend services to sign caller identities,
+// it is required unless env is local or dev
+type IdentityConfig struct {
+	Secret string `yaml:"secret" env:"IDENTITY_SECRET"`
+}
+
+type DatabaseConfig struct {
+	URL     string `yaml:"url" env:"PG_URL" env-required:"true"`
+	PoolMax int    `yaml:"pool_max" env:"PG_POOL_MAX" env-default:"2"`
+}
+
+type StorageConfig struct {
+	// Driver selects where media is stored: s3, local or memory
+	Driver     string             `yaml:"driver" env:"STORAGE_DRIVER" env-default:"s3"`
+	Local      LocalStorageConfig `yaml:"local"`
+	SignedURLs SignedURLConfig    `yaml:"signed_urls"`
+}
+
+type LocalStorageConfig struct {
+	Dir string `yaml:"dir" env:"STORAGE_LOCAL_DIR" env-default:"./data/media"`
+}
+
+// SignedURLConfig configures the URLs of the gateway file route used by the local and memory drivers.
+// A random secret is generated when it is empty, issued URLs then don't survive a restart.
+type SignedURLConfig struct {
+	BaseURL     string        `yaml:"base_url" env:"STORAGE_BASE_URL" env-default:"http://localhost:8080/api/v1/files"`
+	Secret      string        `yaml:"secret" env:"STORAGE_SIGNING_SECRET"`
+	UploadTTL   time.Duration `yaml:"upload_ttl" env-default:"15m"`
+	DownloadTTL time.Duration `yaml:"download_ttl" env-default:"5m"`
+}
+
+// S3 is required by the s3 storage driver
 type S3 struct {
-	ACCESS_KEY        string `env-required:"true" yaml:"access_key"`
-	SECRET_ACCESS_KEY string `env-required:"true" yaml:"secret_access_key"`
-	BUCKET_NAME       string `env-required:"true" yaml:"bucket_name"`
-	ENDPOINT          string `env-required:"true" yaml:"endpoint"`
+	ACCESS_KEY        string `yaml:"access_key"`
+	SECRET_ACCESS_KEY string `yaml:"secret_access_key"`
+	BUCKET_NAME       string `yaml:"bucket_name"`
+	ENDPOINT          string `yaml:"endpoint"`
+	// UPLOAD_URL_TTL is how long a presigned upload URL stays valid
+	UPLOAD_URL_TTL time.Duration `yaml:"upload_url_ttl" env-default:"15m"`
+	// DOWNLOAD_URL_TTL is how long a presigned download URL stays valid
+	DOWNLOAD_URL_TTL time.Duration `yaml:"download_url_ttl" env-default:"5m"`
+}
+
+// UploadPolicy limits the uploads of a media category.
+// Types are matched against the content detected from the magic bytes, extensions include the dot.
+type UploadPolicy struct {
+	Public       bool     `yaml:"public"`
+	AllowedTypes []string `yaml:"allowed_types"`
+	Extensions   []string `yaml:"extensions"`
+	MaxFileSize  int64    `yaml:"max_file_size"`
+	MaxFiles     int      `yaml:"max_files"`
+	MaxTotalSize int64    `yaml:"max_total_size"`
+	// Images enables the image pipeline for the JPEG, PNG and WebP files of the category
+	Images bool `yaml:"images"`
+}
+
+// ImagesConfig configures the pipeline that strips the metadata of uploaded images and generates their thumbnails
+type ImagesConfig struct {
+	// Workers bounds how many images are processed at once across all uploads
+	Workers int `yaml:"workers" env-default:"4"`
+	// ThumbnailSizes are the longest sides of the generated thumbnails in pixels
+	ThumbnailSizes []int `yaml:"thumbnail_sizes" env-default:"160,640"`
+	// MaxDimension scales down originals with a longer side
+	MaxDimension int `yaml:"max_dimension" env-default:"2560"`
+	// MaxPixels rejects images that would take too much memory to decode
+	MaxPixels   int `yaml:"max_pixels" env-default:"40000000"`
+	JPEGQuality int `yaml:"jpeg_quality" env-default:"85"`
+}
+
+// MediaConfig configures the cleanup of presigned uploads that were never completed
+type MediaConfig struct {
+	// PendingTTL is how long a presigned upload may stay pending, it should exceed the upload URL ttl
+	PendingTTL time.Duration `yaml:"pending_ttl" env-default:"24h"`
+	// CleanupInterval is how often stale pending uploads are removed, 0 disables the cleanup
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"10m"`
 }
 
 func MustLoad() *Config {
@@ -0,0 +1,365 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/common"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/tokencache"
+	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type adminRoutes struct {
+	s     authv1.AuthServiceClient
+	log   *slog.Logger
+	cache *tokencache.Cache
+}
+
+func NewAdminRoutes(log *slog.Logger, g Groups, s authv1.AuthServiceClient, cache *tokencache.Cache) {
+	r := &adminRoutes{
+		log:   log,
+		s:     s,
+		cache: cache,
+	}
+
+	users := g.Admin.Group("/admin/users", requirePermission(log, s, permUsersManage))
+	{
+		users.GET("", r.listUsers)
+		users.GET("/:id", r.getUser)
+		users.DELETE("/:id", r.deleteUser)
+		users.POST("/:id/block", r.blockUser)
+		users.POST("/:id/unblock", r.unblockUser)
+	}
+
+	tokens := g.Admin.Group("/admin/service_tokens", requirePermission(log, s, permServiceTokensManage))
+	{
+		tokens.GET("", r.listServiceTokens)
+		tokens.POST("/rotate", r.rotateServiceToken)
+		tokens.DELETE("/:id", r.revokeServiceToken)
+	}
+}
+
+// @Summary     List Users
+// @Description List users with cursor pagination
+// @ID          ListUsers
+// @Tags  	    Admin
+// @Param       cursor query string false "cursor of the next page"
+// @Param       limit query int false "page size, 100 at most"
+// @Param       role query string false "role title"
+// @Param       username_prefix query string false "username prefix"
+// @Param       created_from query string false "created at or after, RFC3339"
+// @Param       created_to query string false "created before, RFC3339"
+// @Param       status query string false "account status: pending, active, blocked or deleted"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.ListUsersResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Failure     503
+// @Router      /admin/users [get]
+func (r *adminRoutes) listUsers(c *gin.Context) {
+	const op = "adminRoutes.listUsers"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req entities.ListUsersQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.ListUsers(c.Request.Context(), req.ToGRPC())
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Get User
+// @Description Get user by id
+// @ID          GetUser
+// @Tags  	    Admin
+// @Param       id path int true "user id"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.GetUserResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /admin/users/{id} [get]
+func (r *adminRoutes) getUser(c *gin.Context) {
+	const op = "adminRoutes.getUser"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	id, ok := pathID(c, log, "user")
+	if !ok {
+		return
+	}
+
+	resp, err := r.s.GetUser(c.Request.Context(), &authv1.GetUserRequest{UserId: id})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Delete User
+// @Description Mark user as deleted and revoke all of their tokens
+// @ID          DeleteUser
+// @Tags  	    Admin
+// @Param       id path int true "user id"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.DeleteUserResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /admin/users/{id} [delete]
+func (r *adminRoutes) deleteUser(c *gin.Context) {
+	const op = "adminRoutes.deleteUser"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	id, ok := pathID(c, log, "user")
+	if !ok {
+		return
+	}
+
+	resp, err := r.s.DeleteUser(c.Request.Context(), &authv1.DeleteUserRequest{UserId: id})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	r.cache.InvalidateUser(id)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Block User
+// @Description Block user and revoke all of their sessions
+// @ID          BlockUser
+// @Tags  	    Admin
+// @Param       id path int true "user id"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.BlockUserResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /admin/users/{id}/block [post]
+func (r *adminRoutes) blockUser(c *gin.Context) {
+	const op = "adminRoutes.blockUser"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	id, ok := pathID(c, log, "user")
+	if !ok {
+		return
+	}
+
+	resp, err := r.s.BlockUser(c.Request.Context(), &authv1.BlockUserRequest{UserId: id})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	r.cache.InvalidateUser(id)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Unblock User
+// @Description Lift a block of a user
+// @ID          UnblockUser
+// @Tags  	    Admin
+// @Param       id path int true "user id"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.UnblockUserResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /admin/users/{id}/unblock [post]
+func (r *adminRoutes) unblockUser(c *gin.Context) {
+	const op = "adminRoutes.unblockUser"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	id, ok := pathID(c, log, "user")
+	if !ok {
+		return
+	}
+
+	resp, err := r.s.UnblockUser(c.Request.Context(), &authv1.UnblockUserRequest{UserId: id})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// userID parses the user id path parameter and responds with 400 when it is invalid
+// @Summary     List Service Tokens
+// @Description List service tokens without their values
+// @ID          ListServiceTokens
+// @Tags  	    Admin
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.ListServiceTokensResponse
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Failure     503
+// @Router      /admin/service_tokens [get]
+func (r *adminRoutes) listServiceTokens(c *gin.Context) {
+	const op = "adminRoutes.listServiceTokens"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	resp, err := r.s.ListServiceTokens(c.Request.Context(), &authv1.ListServiceTokensRequest{})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Rotate Service Token
+// @Description Issue a new token of a service, previous tokens stay valid for the overlap window
+// @ID          RotateServiceToken
+// @Tags  	    Admin
+// @Accept      json
+// @Param 		request body entities.RotateServiceTokenRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.RotateServiceTokenResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /admin/service_tokens/rotate [post]
+func (r *adminRoutes) rotateServiceToken(c *gin.Context) {
+	const op = "adminRoutes.rotateServiceToken"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.RotateServiceTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.RotateServiceToken(c.Request.Context(), req.ToGRPC())
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Revoke Service Token
+// @Description Invalidate a service token immediately
+// @ID          RevokeServiceToken
+// @Tags  	    Admin
+// @Param       id path int true "service token id"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.RevokeServiceTokenResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /admin/service_tokens/{id} [delete]
+func (r *adminRoutes) revokeServiceToken(c *gin.Context) {
+	const op = "adminRoutes.revokeServiceToken"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	id, ok := pathID(c, log, "service token")
+	if !ok {
+		return
+	}
+
+	resp, err := r.s.RevokeServiceToken(c.Request.Context(), &authv1.RevokeServiceTokenRequest{Id: id})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// pathID parses the positive id path parameter, name describes it in errors
+func pathID(c *gin.Context, log *slog.Logger, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		log.Error("bad "+name+" id", slog.String("id", c.Param("id")))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad " + name + " id"})
+		return 0, false
+	}
+	return id, true
+}
@@ -3,38 +3,93 @@ package v1
 import (
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/common"
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/tokencache"
 	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
 	"github.com/gin-gonic/gin"
 )
 
 type authRoutes struct {
-	s   authv1.AuthServiceClient
-	log *slog.Logger
+	s     authv1.AuthServiceClient
+	log   *slog.Logger
+	cache *tokencache.Cache
 }
 
-func NewAuthRoutes(log *slog.Logger, handler *gin.RouterGroup, s authv1.AuthServiceClient) {
+func NewAuthRoutes(log *slog.Logger, g Groups, s authv1.AuthServiceClient, cache *tokencache.Cache) {
 	r := &authRoutes{
-		log: log,
-		s:   s,
+		log:   log,
+		s:     s,
+		cache: cache,
 	}
 
-	g := handler.Group("/auth")
+	public := g.Public.Group("/auth")
 	{
-		g.POST("/register", r.register)
-		g.POST("/login", r.login)
-		g.POST("/logout", r.logout)
-
-		// Additional auth endpoints
-		g.POST("/generate_auth_code", r.generateAuthCode)
-		g.POST("/verify", r.verify)
-		g.POST("/generate_service_token", r.generateServiceToken)
-		g.POST("/get_role", r.getRole)
-		g.POST("/set_role", r.setRole)
-		g.POST("/check_access_token", r.checkAccessToken)
-		g.POST("/check_service_token", r.checkServiceToken)
+		public.POST("/register", r.register)
+		public.POST("/login", r.login)
+		public.POST("/refresh", r.refresh)
+
+		// Two-factor and Telegram login
+		public.POST("/login/totp", r.loginVerifyTOTP)
+		public.POST("/login/telegram", r.loginWithTelegram)
+
+		// Password reset
+		public.POST("/password/reset/request", r.requestPasswordReset)
+		public.POST("/password/reset/confirm", r.confirmPasswordReset)
+
+		// Account activation
+		public.POST("/activate", r.activateAccount)
+		public.POST("/activate/resend", r.resendActivationCode)
+	}
+
+	// Sessions of users who must enable 2FA are only accepted here
+	enrollment := g.Enrollment.Group("/auth")
+	{
+		enrollment.POST("/logout", r.logout)
+		enrollment.POST("/totp/enroll", r.enrollTOTP)
+		enrollment.POST("/totp/confirm", r.confirmTOTP)
+	}
+
+	user := g.User.Group("/auth")
+	{
+		user.POST("/verify", r.verify)
+
+		// Current user
+		user.GET("/me", r.getMe)
+		user.PATCH("/me", r.updateProfile)
+
+		// Session management
+		user.GET("/sessions", r.listSessions)
+		user.DELETE("/sessions", r.revokeAllSessions)
+		user.DELETE("/sessions/:id", r.revokeSession)
+
+		// Password management
+		user.POST("/password/change", r.changePassword)
+
+		// Two-factor authentication
+		user.POST("/totp/disable", r.disableTOTP)
+
+		// Telegram connection
+		user.GET("/telegram", r.getTelegramConnection)
+		user.DELETE("/telegram", r.unlinkTelegram)
+		user.POST("/telegram/relink", r.relinkTelegram)
+	}
+
+	admin := g.Admin.Group("/auth")
+	{
+		admin.POST("/generate_service_token", requirePermission(log, s, permServiceTokensGenerate), r.generateServiceToken)
+		admin.POST("/set_role", requirePermission(log, s, permRolesAssign), r.setRole)
+	}
+
+	internal := g.Internal.Group("/auth")
+	{
+		internal.POST("/generate_auth_code", r.generateAuthCode)
+		internal.POST("/get_role", r.getRole)
+		internal.POST("/check_access_token", r.checkAccessToken)
+		internal.POST("/check_service_token", r.checkServiceToken)
+		internal.POST("/telegram/resolve", r.resolveTelegramUser)
 	}
 }
 
@@ -86,7 +141,9 @@ func (r *authRoutes) register(c *gin.Context) {
 // @Success     200 {object} authv1.LoginResponse
 // @Failure     400
 // @Failure     401
+// @Failure     403
 // @Failure     404
+// @Failure     429
 // @Failure     500
 // @Failure     503
 // @Router      /auth/login [post]
@@ -104,11 +161,55 @@ func (r *authRoutes) login(c *gin.Context) {
 		return
 	}
 
-	resp, err := r.s.Login(c.Request.Context(), req.ToGRPC())
+	resp, err := r.s.Login(c.Request.Context(), req.ToGRPC(c.Request.UserAgent(), c.ClientIP()))
 	if err != nil {
-		code, err := common.GetProtoErrWithStatusCode(err)
+		setRetryAfter(c, err)
+		code, httpErr := common.GetProtoErrWithStatusCode(err)
+		log.Error(httpErr.Error())
+		c.JSON(code, protoErrorBody(err, httpErr))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Login With Telegram
+// @Description Log in or register with a Telegram Login Widget payload
+// @ID          LoginWithTelegram
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		login body entities.TelegramLoginRequest false "login"
+// @Produce     json
+// @Success     200 {object} authv1.LoginResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     429
+// @Failure     500
+// @Failure     501
+// @Failure     503
+// @Router      /auth/login/telegram [post]
+func (r *authRoutes) loginWithTelegram(c *gin.Context) {
+	const op = "authRoutes.loginWithTelegram"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.TelegramLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Error(err.Error())
-		c.JSON(code, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.LoginWithTelegram(c.Request.Context(), req.ToGRPC(c.Request.UserAgent(), c.ClientIP()))
+	if err != nil {
+		setRetryAfter(c, err)
+		code, httpErr := common.GetProtoErrWithStatusCode(err)
+		log.Error(httpErr.Error())
+		c.JSON(code, protoErrorBody(err, httpErr))
 		return
 	}
 
@@ -116,14 +217,13 @@ func (r *authRoutes) login(c *gin.Context) {
 }
 
 // @Summary     Logout
-// @Description Logout
+// @Description Revoke the session of the bearer token
 // @ID          Logout
 // @Tags  	    Auth
-// @Accept      json
-// @Param 		logout body entities.LogoutRequest false "logout"
 // @Produce     json
+// @Security    ApiKeyAuth
 // @Success     200 {object} authv1.LogoutResponse
-// @Failure     400
+// @Failure     401
 // @Failure     404
 // @Failure     500
 // @Failure     503
@@ -135,18 +235,55 @@ func (r *authRoutes) logout(c *gin.Context) {
 		slog.String("op", op),
 	)
 
-	var req *entities.LogoutRequest
+	token := c.GetString(_accessTokenKey)
+
+	resp, err := r.s.Logout(c.Request.Context(), &authv1.LogoutRequest{AccessToken: token})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	r.cache.Invalidate(token)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Refresh
+// @Description Rotate refresh token and issue a new token pair
+// @ID          Refresh
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		refresh body entities.RefreshRequest false "refresh"
+// @Produce     json
+// @Success     200 {object} authv1.RefreshResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /auth/refresh [post]
+func (r *authRoutes) refresh(c *gin.Context) {
+	const op = "authRoutes.refresh"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.RefreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Error(err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
 		return
 	}
 
-	resp, err := r.s.Logout(c.Request.Context(), req.ToGRPC())
+	resp, err := r.s.Refresh(c.Request.Context(), req.ToGRPC(c.Request.UserAgent(), c.ClientIP()))
 	if err != nil {
-		code, err := common.GetProtoErrWithStatusCode(err)
-		log.Error(err.Error())
-		c.JSON(code, gin.H{"error": err.Error()})
+		code, httpErr := common.GetProtoErrWithStatusCode(err)
+		log.Error(httpErr.Error())
+		c.JSON(code, protoErrorBody(err, httpErr))
 		return
 	}
 
@@ -154,18 +291,20 @@ func (r *authRoutes) logout(c *gin.Context) {
 }
 
 // @Summary     Generate Auth Code
-// @Description Generate authentication code
+// @Description Generate a one-time code for a Telegram user
 // @ID          GenerateAuthCode
-// @Tags  	    Auth
+// @Tags  	    Internal
 // @Accept      json
-// @Param 		request body authv1.GenerateAuthCodeRequest false "request"
+// @Param 		request body entities.GenerateAuthCodeRequest false "request"
 // @Produce     json
+// @Security    ServiceTokenAuth
 // @Success     200 {object} authv1.GenerateAuthCodeResponse
 // @Failure     400
-// @Failure     404
+// @Failure     401
+// @Failure     403
 // @Failure     500
 // @Failure     503
-// @Router      /auth/generate_auth_code [post]
+// @Router      /internal/auth/generate_auth_code [post]
 func (r *authRoutes) generateAuthCode(c *gin.Context) {
 	const op = "authRoutes.generateAuthCode"
 
@@ -173,14 +312,14 @@ func (r *authRoutes) generateAuthCode(c *gin.Context) {
 		slog.String("op", op),
 	)
 
-	var req *authv1.GenerateAuthCodeRequest
+	var req *entities.GenerateAuthCodeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Error(err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
 		return
 	}
 
-	resp, err := r.s.GenerateAuthCode(c.Request.Context(), req)
+	resp, err := r.s.GenerateAuthCode(c.Request.Context(), req.ToGRPC(c.GetString(_serviceTokenKey)))
 	if err != nil {
 		code, err := common.GetProtoErrWithStatusCode(err)
 		log.Error(err.Error())
@@ -192,15 +331,18 @@ func (r *authRoutes) generateAuthCode(c *gin.Context) {
 }
 
 // @Summary     Verify
-// @Description Verify authentication
+// @Description Verify a code issued to the Telegram account of the current user
 // @ID          Verify
 // @Tags  	    Auth
 // @Accept      json
-// @Param 		request body authv1.VerifyRequest false "request"
+// @Security    ApiKeyAuth
+// @Param 		request body entities.VerifyRequest false "request"
 // @Produce     json
 // @Success     200 {object} authv1.VerifyResponse
 // @Failure     400
+// @Failure     401
 // @Failure     404
+// @Failure     429
 // @Failure     500
 // @Failure     503
 // @Router      /auth/verify [post]
@@ -211,15 +353,16 @@ func (r *authRoutes) verify(c *gin.Context) {
 		slog.String("op", op),
 	)
 
-	var req *authv1.VerifyRequest
+	var req *entities.VerifyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Error(err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
 		return
 	}
 
-	resp, err := r.s.Verify(c.Request.Context(), req)
+	resp, err := r.s.Verify(c.Request.Context(), req.ToGRPC(c.GetString(_accessTokenKey)))
 	if err != nil {
+		setRetryAfter(c, err)
 		code, err := common.GetProtoErrWithStatusCode(err)
 		log.Error(err.Error())
 		c.JSON(code, gin.H{"error": err.Error()})
@@ -230,15 +373,18 @@ func (r *authRoutes) verify(c *gin.Context) {
 }
 
 // @Summary     Generate Service Token
-// @Description Generate service token
+// @Description Issue the first token of a service, existing services must rotate their token
 // @ID          GenerateServiceToken
 // @Tags  	    Auth
 // @Accept      json
-// @Param 		request body authv1.GenerateServiceTokenRequest false "request"
+// @Param 		request body entities.GenerateServiceTokenRequest false "request"
 // @Produce     json
+// @Security    ApiKeyAuth
 // @Success     200 {object} authv1.GenerateServiceTokenResponse
 // @Failure     400
-// @Failure     404
+// @Failure     401
+// @Failure     403
+// @Failure     409
 // @Failure     500
 // @Failure     503
 // @Router      /auth/generate_service_token [post]
@@ -249,14 +395,14 @@ func (r *authRoutes) generateServiceToken(c *gin.Context) {
 		slog.String("op", op),
 	)
 
-	var req *authv1.GenerateServiceTokenRequest
+	var req *entities.GenerateServiceTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Error(err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
 		return
 	}
 
-	resp, err := r.s.GenerateServiceToken(c.Request.Context(), req)
+	resp, err := r.s.GenerateServiceToken(c.Request.Context(), req.ToGRPC())
 	if err != nil {
 		code, err := common.GetProtoErrWithStatusCode(err)
 		log.Error(err.Error())
@@ -270,16 +416,18 @@ func (r *authRoutes) generateServiceToken(c *gin.Context) {
 // @Summary     Get Role
 // @Description Get user role
 // @ID          GetRole
-// @Tags  	    Auth
+// @Tags  	    Internal
 // @Accept      json
 // @Param 		request body authv1.GetRoleRequest false "request"
 // @Produce     json
+// @Security    ServiceTokenAuth
 // @Success     200 {object} authv1.GetRoleResponse
 // @Failure     400
+// @Failure     401
 // @Failure     404
 // @Failure     500
 // @Failure     503
-// @Router      /auth/get_role [post]
+// @Router      /internal/auth/get_role [post]
 func (r *authRoutes) getRole(c *gin.Context) {
 	const op = "authRoutes.getRole"
 
@@ -312,8 +460,11 @@ func (r *authRoutes) getRole(c *gin.Context) {
 // @Accept      json
 // @Param 		request body authv1.SetRoleRequest false "request"
 // @Produce     json
+// @Security    ApiKeyAuth
 // @Success     200 {object} authv1.SetRoleResponse
 // @Failure     400
+// @Failure     401
+// @Failure     403
 // @Failure     404
 // @Failure     500
 // @Failure     503
@@ -340,22 +491,27 @@ func (r *authRoutes) setRole(c *gin.Context) {
 		return
 	}
 
+	// Cached checks carry the role, the old one must not outlive the change
+	r.cache.InvalidateUser(req.UserId)
+
 	c.JSON(http.StatusOK, resp)
 }
 
 // @Summary     Check Access Token
 // @Description Check access token validity
 // @ID          CheckAccessToken
-// @Tags  	    Auth
+// @Tags  	    Internal
 // @Accept      json
 // @Param 		request body authv1.CheckAccessTokenRequest false "request"
 // @Produce     json
+// @Security    ServiceTokenAuth
 // @Success     200 {object} authv1.CheckAccessTokenResponse
 // @Failure     400
+// @Failure     401
 // @Failure     404
 // @Failure     500
 // @Failure     503
-// @Router      /auth/check_access_token [post]
+// @Router      /internal/auth/check_access_token [post]
 func (r *authRoutes) checkAccessToken(c *gin.Context) {
 	const op = "authRoutes.checkAccessToken"
 
@@ -384,16 +540,18 @@ func (r *authRoutes) checkAccessToken(c *gin.Context) {
 // @Summary     Check Service Token
 // @Description Check service token validity
 // @ID          CheckServiceToken
-// @Tags  	    Auth
+// @Tags  	    Internal
 // @Accept      json
 // @Param 		request body authv1.CheckServiceTokenRequest false "request"
 // @Produce     json
+// @Security    ServiceTokenAuth
 // @Success     200 {object} authv1.CheckServiceTokenResponse
 // @Failure     400
+// @Failure     401
 // @Failure     404
 // @Failure     500
 // @Failure     503
-// @Router      /auth/check_service_token [post]
+// @Router      /internal/auth/check_service_token [post]
 func (r *authRoutes) checkServiceToken(c *gin.Context) {
 	const op = "authRoutes.checkServiceToken"
 
@@ -418,3 +576,345 @@ func (r *authRoutes) checkServiceToken(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+// @Summary     List Sessions
+// @Description List active sessions of the current user
+// @ID          ListSessions
+// @Tags  	    Auth
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.ListSessionsResponse
+// @Failure     401
+// @Failure     500
+// @Failure     503
+// @Router      /auth/sessions [get]
+func (r *authRoutes) listSessions(c *gin.Context) {
+	const op = "authRoutes.listSessions"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	req := &authv1.ListSessionsRequest{
+		AccessToken: c.GetString(_accessTokenKey),
+	}
+
+	resp, err := r.s.ListSessions(c.Request.Context(), req)
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Revoke Session
+// @Description Revoke one session of the current user
+// @ID          RevokeSession
+// @Tags  	    Auth
+// @Param       id path int true "session id"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.RevokeSessionResponse
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /auth/sessions/{id} [delete]
+func (r *authRoutes) revokeSession(c *gin.Context) {
+	const op = "authRoutes.revokeSession"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad session id"})
+		return
+	}
+
+	req := &authv1.RevokeSessionRequest{
+		AccessToken: c.GetString(_accessTokenKey),
+		SessionId:   id,
+	}
+
+	resp, err := r.s.RevokeSession(c.Request.Context(), req)
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := CurrentUser(c)
+	r.cache.InvalidateUser(user.UserID)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Revoke All Sessions
+// @Description Revoke every session of the current user
+// @ID          RevokeAllSessions
+// @Tags  	    Auth
+// @Param       keep_current query bool false "keep the current session"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.RevokeAllSessionsResponse
+// @Failure     400
+// @Failure     401
+// @Failure     500
+// @Failure     503
+// @Router      /auth/sessions [delete]
+func (r *authRoutes) revokeAllSessions(c *gin.Context) {
+	const op = "authRoutes.revokeAllSessions"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	keepCurrent, err := strconv.ParseBool(c.DefaultQuery("keep_current", "false"))
+	if err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad keep_current value"})
+		return
+	}
+
+	req := &authv1.RevokeAllSessionsRequest{
+		AccessToken: c.GetString(_accessTokenKey),
+		KeepCurrent: keepCurrent,
+	}
+
+	resp, err := r.s.RevokeAllSessions(c.Request.Context(), req)
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := CurrentUser(c)
+	r.cache.InvalidateUser(user.UserID)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Change Password
+// @Description Change password of the current user
+// @ID          ChangePassword
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.ChangePasswordRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.ChangePasswordResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Failure     503
+// @Router      /auth/password/change [post]
+func (r *authRoutes) changePassword(c *gin.Context) {
+	const op = "authRoutes.changePassword"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.ChangePassword(c.Request.Context(), req.ToGRPC(c.GetString(_accessTokenKey)))
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := CurrentUser(c)
+	r.cache.InvalidateUser(user.UserID)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Request Password Reset
+// @Description Send a one-time password reset code
+// @ID          RequestPasswordReset
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.RequestPasswordResetRequest false "request"
+// @Produce     json
+// @Success     200 {object} authv1.RequestPasswordResetResponse
+// @Failure     400
+// @Failure     500
+// @Failure     503
+// @Router      /auth/password/reset/request [post]
+func (r *authRoutes) requestPasswordReset(c *gin.Context) {
+	const op = "authRoutes.requestPasswordReset"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.RequestPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.RequestPasswordReset(c.Request.Context(), req.ToGRPC())
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Confirm Password Reset
+// @Description Set a new password using a reset code
+// @ID          ConfirmPasswordReset
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.ConfirmPasswordResetRequest false "request"
+// @Produce     json
+// @Success     200 {object} authv1.ConfirmPasswordResetResponse
+// @Failure     400
+// @Failure     403
+// @Failure     429
+// @Failure     500
+// @Failure     503
+// @Router      /auth/password/reset/confirm [post]
+func (r *authRoutes) confirmPasswordReset(c *gin.Context) {
+	const op = "authRoutes.confirmPasswordReset"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.ConfirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.ConfirmPasswordReset(c.Request.Context(), req.ToGRPC())
+	if err != nil {
+		setRetryAfter(c, err)
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Activate Account
+// @Description Activate a pending account using the code sent after registration
+// @ID          ActivateAccount
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.ActivateAccountRequest false "request"
+// @Produce     json
+// @Success     200 {object} authv1.ActivateAccountResponse
+// @Failure     400
+// @Failure     403
+// @Failure     429
+// @Failure     500
+// @Failure     503
+// @Router      /auth/activate [post]
+func (r *authRoutes) activateAccount(c *gin.Context) {
+	const op = "authRoutes.activateAccount"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.ActivateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.ActivateAccount(c.Request.Context(), req.ToGRPC())
+	if err != nil {
+		setRetryAfter(c, err)
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Resend Activation Code
+// @Description Send a new activation code to a pending account
+// @ID          ResendActivationCode
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.ResendActivationCodeRequest false "request"
+// @Produce     json
+// @Success     200 {object} authv1.ResendActivationCodeResponse
+// @Failure     400
+// @Failure     429
+// @Failure     500
+// @Failure     503
+// @Router      /auth/activate/resend [post]
+func (r *authRoutes) resendActivationCode(c *gin.Context) {
+	const op = "authRoutes.resendActivationCode"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.ResendActivationCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.ResendActivationCode(c.Request.Context(), req.ToGRPC())
+	if err != nil {
+		setRetryAfter(c, err)
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// protoErrorBody builds an error response, adding the machine-readable
+// reason of the proto error as "code" when there is one
+func protoErrorBody(protoErr, err error) gin.H {
+	body := gin.H{"error": err.Error()}
+	if reason := common.GetErrorReason(protoErr); reason != "" {
+		body["code"] = reason
+	}
+	return body
+}
+
+// setRetryAfter exposes the lockout delay of a rate limited request
+func setRetryAfter(c *gin.Context, err error) {
+	if seconds, ok := common.GetRetryAfter(err); ok {
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+}
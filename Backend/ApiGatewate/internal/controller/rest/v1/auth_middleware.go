@@ -4,29 +4,194 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/common"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/identity"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/jwt"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/tokencache"
 	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
 	"github.com/gin-gonic/gin"
 )
 
-func authMiddleware(log *slog.Logger, s authv1.AuthServiceClient) gin.HandlerFunc {
+const (
+	// _accessTokenKey is the gin context key holding the validated bearer token
+	_accessTokenKey = "access_token"
+	// _identityKey is the gin context key holding the authenticated identity.Identity
+	_identityKey = "identity"
+	// _serviceTokenKey is the gin context key holding the validated service token
+	_serviceTokenKey = "service_token"
+	// _serviceNameKey is the gin context key holding the name of the calling service
+	_serviceNameKey = "service_name"
+
+	// _serviceTokenHeader carries the service token of internal requests
+	_serviceTokenHeader = "X-Service-Token"
+)
+
+// Permissions checked by the gateway
+const (
+	permRolesAssign           = "roles:assign"
+	permRolesManage           = "roles:manage"
+	permServiceTokensGenerate = "service_tokens:generate"
+	permServiceTokensManage   = "service_tokens:manage"
+	permUsersManage           = "users:manage"
+)
+
+// authMiddleware validates the bearer token. Signed tokens are verified locally when
+// a verifier is configured, the auth service is then only asked for revocation.
+// Answers of the auth service are cached per token. The caller identity is stored
+// in the gin context and in the request context for downstream calls.
+func authMiddleware(log *slog.Logger, s authv1.AuthServiceClient, v *jwt.Verifier, cache *tokencache.Cache) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authH := c.GetHeader("Authorization")
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
 		defer cancel()
 
-		if !strings.Contains(authH, "Bearer ") {
+		token, ok := bearerToken(c)
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "bad token"})
 			return
 		}
 
-		token := strings.Split(authH, "Bearer ")[1]
-		slog.Info(token)
+		if v != nil {
+			claims, err := v.Verify(ctx, token)
+			if err != nil {
+				log.Error(err.Error())
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "bad token"})
+				return
+			}
+
+			if claims.TOTPPending {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "two-factor authentication must be enabled first",
+					"code":  "TOTP_ENROLLMENT_REQUIRED",
+				})
+				return
+			}
+
+			if !v.RevocationCheck() {
+				userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+				if err != nil {
+					log.Error(err.Error())
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "bad token"})
+					return
+				}
+
+				setIdentity(c, token, identity.Identity{UserID: userID, Role: claims.Role})
+				c.Next()
+				return
+			}
+		}
 
-		_, err := s.CheckAccessToken(ctx, &authv1.CheckAccessTokenRequest{AccessToken: token})
+		id, err := cache.Get(ctx, token, func(ctx context.Context) (identity.Identity, error) {
+			resp, err := s.CheckAccessToken(ctx, &authv1.CheckAccessTokenRequest{AccessToken: token})
+			if err != nil {
+				return identity.Identity{}, err
+			}
+			return identity.Identity{UserID: resp.UserId, Role: resp.Role}, nil
+		})
+		if err != nil {
+			status, httpErr := common.GetProtoErrWithStatusCode(err)
+			log.Error(httpErr.Error())
+			c.AbortWithStatusJSON(status, protoErrorBody(err, httpErr))
+			return
+		}
+
+		setIdentity(c, token, id)
+		c.Next()
+	}
+}
+
+// bearerMiddleware only extracts the bearer token, the routes behind it are served
+// to sessions pending 2FA enrollment and leave the token check to the auth service
+func bearerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "bad token"})
+			return
+		}
+
+		c.Set(_accessTokenKey, token)
+		c.Next()
+	}
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	authH := c.GetHeader("Authorization")
+	if !strings.Contains(authH, "Bearer ") {
+		return "", false
+	}
+
+	return strings.Split(authH, "Bearer ")[1], true
+}
+
+func setIdentity(c *gin.Context, token string, id identity.Identity) {
+	c.Set(_accessTokenKey, token)
+	c.Set(_identityKey, id)
+	c.Request = c.Request.WithContext(identity.WithIdentity(c.Request.Context(), id))
+}
+
+// CurrentUser returns the caller authenticated by authMiddleware
+func CurrentUser(c *gin.Context) (identity.Identity, bool) {
+	v, ok := c.Get(_identityKey)
+	if !ok {
+		return identity.Identity{}, false
+	}
+
+	id, ok := v.(identity.Identity)
+	return id, ok
+}
+
+// serviceMiddleware admits requests of services presenting a valid service token
+func serviceMiddleware(log *slog.Logger, s authv1.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(_serviceTokenHeader)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "service token is required"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+
+		resp, err := s.CheckServiceToken(ctx, &authv1.CheckServiceTokenRequest{ServiceToken: token})
+		if err != nil {
+			status, err := common.GetProtoErrWithStatusCode(err)
+			log.Error(err.Error())
+			c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !resp.Valid {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "bad service token"})
+			return
+		}
+
+		c.Set(_serviceTokenKey, token)
+		c.Set(_serviceNameKey, resp.ServiceName)
+		c.Next()
+	}
+}
+
+// requirePermission rejects requests of users whose role does not grant the permission.
+// It must run after authMiddleware.
+func requirePermission(log *slog.Logger, s authv1.AuthServiceClient, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+
+		resp, err := s.CheckPermission(ctx, &authv1.CheckPermissionRequest{
+			UserId:     user.UserID,
+			Permission: permission,
+		})
 		if err != nil {
 			status, err := common.GetProtoErrWithStatusCode(err)
 			log.Error(err.Error())
@@ -34,6 +199,11 @@ func authMiddleware(log *slog.Logger, s authv1.AuthServiceClient) gin.HandlerFun
 			return
 		}
 
+		if !resp.Allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+			return
+		}
+
 		c.Next()
 	}
 }
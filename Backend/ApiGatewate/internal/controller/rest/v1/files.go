@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// _checksumHeader carries the base64 SHA-256 of a presigned upload, named as in S3
+const _checksumHeader = "X-Amz-Checksum-Sha256"
+
+// fileRoutes serves the presigned URLs of storages without their own endpoint
+type fileRoutes struct {
+	log *slog.Logger
+	st  storage.SignedStorage
+}
+
+func NewFileRoutes(log *slog.Logger, g Groups, st storage.SignedStorage) {
+	r := &fileRoutes{
+		log: log,
+		st:  st,
+	}
+
+	public := g.Public.Group("/files")
+	{
+		public.GET("/*key", r.get)
+		public.PUT("/*key", r.put)
+	}
+}
+
+// @Summary     Download file
+// @Description Download a file through a URL signed by the gateway
+// @ID          Download file
+// @Tags  	    media
+// @Param 		key path string true "object key"
+// @Param 		expires query int true "expiry of the url"
+// @Param 		signature query string true "signature of the url"
+// @Produce     octet-stream
+// @Success     200
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /files/{key} [get]
+func (r *fileRoutes) get(c *gin.Context) {
+	const op = "fileRoutes.get"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	err := r.verify(c, storage.SignedRequest{Method: http.MethodGet, Key: key})
+	if err != nil {
+		log.Info(err.Error())
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	body, obj, err := r.st.Get(c.Request.Context(), key)
+	if err != nil {
+		fileError(c, log, err)
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, obj.Size, obj.ContentType, body, map[string]string{
+		"Cache-Control":          "private, no-store",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// @Summary     Upload file
+// @Description Upload a file through a URL presigned by /media/presign.
+// @Description Content-Type, Content-Length and X-Amz-Checksum-Sha256 must match the presigned upload.
+// @ID          Upload file
+// @Tags  	    media
+// @Param 		key path string true "object key"
+// @Param 		expires query int true "expiry of the url"
+// @Param 		signature query string true "signature of the url"
+// @Accept      octet-stream
+// @Success     200
+// @Failure     400
+// @Failure     403
+// @Failure     500
+// @Router      /files/{key} [put]
+func (r *fileRoutes) put(c *gin.Context) {
+	const op = "fileRoutes.put"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	req := storage.SignedRequest{
+		Method:      http.MethodPut,
+		Key:         key,
+		ContentType: c.GetHeader("Content-Type"),
+		Size:        c.Request.ContentLength,
+		Checksum:    c.GetHeader(_checksumHeader),
+	}
+
+	err := r.verify(c, req)
+	if err != nil {
+		log.Info(err.Error())
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The storage checks the content before it replaces anything stored under the key
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, req.Size)
+	err = r.st.Put(c.Request.Context(), key, body, req.Size, req.ContentType, req.Checksum)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "body is longer than Content-Length"})
+		case errors.Is(err, storage.ErrContentMismatch):
+			log.Info("uploaded content does not match the signed request", slog.String("key", key))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "content does not match Content-Length or " + _checksumHeader})
+		default:
+			fileError(c, log, err)
+		}
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (r *fileRoutes) verify(c *gin.Context, req storage.SignedRequest) error {
+	if !storage.ValidKey(req.Key) {
+		return storage.ErrBadSignature
+	}
+
+	return r.st.Signer().Verify(req, c.Query(storage.QueryExpires), c.Query(storage.QuerySignature))
+}
+
+func fileError(c *gin.Context, log *slog.Logger, err error) {
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		c.JSON(http.StatusNotFound, gin.H{"error": storage.ErrNotFound.Error()})
+		return
+	}
+
+	log.Error(err.Error())
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
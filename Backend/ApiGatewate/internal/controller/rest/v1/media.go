@@ -2,119 +2,466 @@ package v1
 
 import (
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"strings"
 
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/common"
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
-	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/s3"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/imaging"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/upload"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/services"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
-	maxFilesSize    = 10 << 20
-	photosArrLenght = 5
+	// maxFilesMemory is the part of a multipart form kept in memory, the rest goes to temporary files
+	maxFilesMemory = 10 << 20
+	// multipartOverhead is the room left for boundaries and part headers above the files size limit
+	multipartOverhead = 1 << 20
 )
 
-var (
-	ErrHudgeFiles       = errors.New("hudge files")
-	ErrWrongContentType = errors.New("content type must be multipart/form-data")
-	ErrNoFiles          = errors.New("you must send at least one file")
-)
+var ErrWrongContentType = errors.New("content type must be multipart/form-data")
 
 type mediaRoutes struct {
 	log *slog.Logger
-	s3  *s3.S3Storage
+	s   *services.MediaService
 }
 
-func NewMediaRoutes(log *slog.Logger, handler *gin.RouterGroup, s3 *s3.S3Storage) {
+func NewMediaRoutes(log *slog.Logger, g Groups, s *services.MediaService) {
 	r := &mediaRoutes{
 		log: log,
-		s3:  s3,
+		s:   s,
 	}
 
-	g := handler.Group("/media")
+	user := g.User.Group("/media")
 	{
-		g.POST("/upload", r.upload)
+		user.POST("/upload", r.upload)
+		user.POST("/presign", r.presign)
+		user.POST("/complete", r.complete)
+		user.GET("/download", r.download)
+		user.GET("", r.list)
+		user.GET("/:id", r.get)
+		user.DELETE("/:id", r.delete)
 	}
 }
 
-func (r *mediaRoutes) getFiles(c *gin.Context) ([]entities.File, error) {
-	err := c.Request.ParseMultipartForm(maxFilesSize)
-	if err != nil {
-		return nil, ErrHudgeFiles
-	}
+// getFiles parses the files of the form, rejecting bodies over the total size limit of the policy.
+// The returned files must be closed with closeFiles.
+func (r *mediaRoutes) getFiles(c *gin.Context, policy *upload.Policy) ([]entities.File, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, policy.MaxTotalSize+multipartOverhead)
 
-	multipartFormData, err := c.MultipartForm()
+	err := c.Request.ParseMultipartForm(maxFilesMemory)
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, upload.ErrTotalTooLarge
+		}
 		return nil, err
 	}
-	arr := multipartFormData.File["files"]
 
-	if len(arr) == 0 {
-		return nil, ErrNoFiles
-	}
+	arr := c.Request.MultipartForm.File["files"]
 
-	files := make([]entities.File, 0, photosArrLenght)
+	sizes := make([]int64, 0, len(arr))
 	for _, fileHeader := range arr {
-		file := entities.File{
-			Filename: fileHeader.Filename,
-		}
+		sizes = append(sizes, fileHeader.Size)
+	}
 
+	err = policy.CheckBatch(sizes...)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]entities.File, 0, len(arr))
+	for _, fileHeader := range arr {
 		f, err := fileHeader.Open()
 		if err != nil {
+			closeFiles(files)
 			return nil, err
 		}
-		defer f.Close()
 
-		file.File = f
-		files = append(files, file)
+		files = append(files, entities.File{
+			Filename: fileHeader.Filename,
+			Size:     fileHeader.Size,
+			File:     f,
+		})
 	}
 
 	return files, nil
 }
 
+func closeFiles(files []entities.File) {
+	for _, file := range files {
+		if closer, ok := file.File.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
 // @Summary     Upload media
-// @Description Upload media
+// @Description Upload files of a media category. The category limits the number, size, extension and type of the files,
+// @Description the type is detected from the content. Files of private categories get time limited URLs.
+// @Description Images of categories with the image pipeline are stored upright without their metadata, along with thumbnails.
+// @Description The files are stored all or none, nothing is kept when one of them fails.
 // @ID          Upload media
 // @Tags  	    media
+// @Param 		category query string true "media category" Enums(avatar, photo, document, audio)
 // @Param 		files formData []file false "files"
 // @Accept      mpfd
 // @Produce     json
+// @Security    ApiKeyAuth
 // @Success     200 {object} entities.UploadResponse
 // @Failure     400
+// @Failure     401
+// @Failure     413
+// @Failure     415
 // @Failure     500
 // @Failure     503
 // @Router      /media/upload [post]
 func (r *mediaRoutes) upload(c *gin.Context) {
+	const op = "mediaRoutes.upload"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	contentType := c.Request.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "multipart/form-data") {
-		slog.Error(ErrWrongContentType.Error())
+		log.Info(ErrWrongContentType.Error())
 		c.JSON(http.StatusBadRequest, gin.H{"error": ErrWrongContentType.Error()})
 		return
 	}
 
-	files, err := r.getFiles(c)
+	category := c.Query("category")
+	policy, err := r.s.Policy(category)
 	if err != nil {
-		slog.Error(err.Error())
-		if errors.Is(err, ErrHudgeFiles) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": ErrHudgeFiles.Error()})
-			return
-		}
-		if errors.Is(err, ErrNoFiles) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": ErrNoFiles.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		mediaError(c, log, err)
 		return
 	}
 
-	fls, err := r.s3.Save(files)
+	files, err := r.getFiles(c, policy)
 	if err != nil {
-		slog.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		mediaError(c, log, err)
+		return
+	}
+	defer closeFiles(files)
+
+	fls, err := r.s.Upload(c.Request.Context(), user.UserID, category, files)
+	if err != nil {
+		mediaError(c, log, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, entities.UploadResponse{Files: fls})
 }
+
+// @Summary     Presign upload
+// @Description Get a presigned URL to upload a private file directly to the storage.
+// @Description The file must be sent with a PUT to the URL with the returned headers, then confirmed with /media/complete.
+// @ID          Presign upload
+// @Tags  	    media
+// @Accept      json
+// @Param 		request body entities.PresignUploadRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} entities.PresignUploadResponse
+// @Failure     400
+// @Failure     401
+// @Failure     413
+// @Failure     415
+// @Failure     500
+// @Failure     503
+// @Router      /media/presign [post]
+func (r *mediaRoutes) presign(c *gin.Context) {
+	const op = "mediaRoutes.presign"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req *entities.PresignUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.Presign(c.Request.Context(), user.UserID, req)
+	if err != nil {
+		mediaError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Complete upload
+// @Description Confirm that a presigned upload reached the storage
+// @ID          Complete upload
+// @Tags  	    media
+// @Accept      json
+// @Param 		request body entities.CompleteUploadRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} entities.Media
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     413
+// @Failure     415
+// @Failure     500
+// @Failure     503
+// @Router      /media/complete [post]
+func (r *mediaRoutes) complete(c *gin.Context) {
+	const op = "mediaRoutes.complete"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req *entities.CompleteUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	m, err := r.s.Complete(c.Request.Context(), user.UserID, req.Key)
+	if err != nil {
+		mediaError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// @Summary     Download URL
+// @Description Get a time limited URL to download a private file
+// @ID          Download URL
+// @Tags  	    media
+// @Param 		key query string true "object key"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} entities.DownloadURLResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /media/download [get]
+func (r *mediaRoutes) download(c *gin.Context) {
+	const op = "mediaRoutes.download"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	key := c.Query("key")
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "key is required"})
+		return
+	}
+
+	m, err := r.s.DownloadURL(c.Request.Context(), user.UserID, key)
+	if err != nil {
+		mediaError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entities.DownloadURLResponse{
+		URL:       m.URL,
+		ExpiresAt: m.ExpiresAt,
+	})
+}
+
+// @Summary     List media
+// @Description List uploaded media of the current user, newest first
+// @ID          List media
+// @Tags  	    media
+// @Param 		limit query int false "page size, 50 by default"
+// @Param 		offset query int false "number of skipped files"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} entities.ListMediaResponse
+// @Failure     400
+// @Failure     401
+// @Failure     500
+// @Failure     503
+// @Router      /media [get]
+func (r *mediaRoutes) list(c *gin.Context) {
+	const op = "mediaRoutes.list"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req entities.ListMediaQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	media, err := r.s.List(c.Request.Context(), user.UserID, req.Limit, req.Offset)
+	if err != nil {
+		mediaError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entities.ListMediaResponse{Media: media})
+}
+
+// @Summary     Get media
+// @Description Get a file with the URL to fetch it, private files get a time limited URL.
+// @Description Only the owner and users with the media:manage permission have access.
+// @ID          Get media
+// @Tags  	    media
+// @Param 		id path string true "media id"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} entities.MediaResponse
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /media/{id} [get]
+func (r *mediaRoutes) get(c *gin.Context) {
+	const op = "mediaRoutes.get"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	m, err := r.s.Get(c.Request.Context(), user.UserID, c.Param("id"))
+	if err != nil {
+		mediaError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// @Summary     Delete media
+// @Description Delete a file from the storage.
+// @Description Only the owner and users with the media:manage permission have access.
+// @ID          Delete media
+// @Tags  	    media
+// @Param 		id path string true "media id"
+// @Security    ApiKeyAuth
+// @Success     204
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /media/{id} [delete]
+func (r *mediaRoutes) delete(c *gin.Context) {
+	const op = "mediaRoutes.delete"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err := r.s.Delete(c.Request.Context(), user.UserID, c.Param("id"))
+	if err != nil {
+		mediaError(c, log, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// uploadErrors maps the policy violations to their status and code
+var uploadErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{upload.ErrUnknownCategory, http.StatusBadRequest, "UNKNOWN_CATEGORY"},
+	{upload.ErrNoFiles, http.StatusBadRequest, "NO_FILES"},
+	{upload.ErrTooManyFiles, http.StatusBadRequest, "TOO_MANY_FILES"},
+	{upload.ErrFileTooLarge, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE"},
+	{upload.ErrTotalTooLarge, http.StatusRequestEntityTooLarge, "TOTAL_TOO_LARGE"},
+	{upload.ErrExtensionNotAllowed, http.StatusUnsupportedMediaType, "EXTENSION_NOT_ALLOWED"},
+	{upload.ErrTypeNotAllowed, http.StatusUnsupportedMediaType, "TYPE_NOT_ALLOWED"},
+	{services.ErrUploadMismatch, http.StatusBadRequest, "UPLOAD_MISMATCH"},
+	{services.ErrBadChecksum, http.StatusBadRequest, "BAD_CHECKSUM"},
+	{imaging.ErrInvalidImage, http.StatusUnsupportedMediaType, "INVALID_IMAGE"},
+	{imaging.ErrImageTooLarge, http.StatusRequestEntityTooLarge, "IMAGE_TOO_LARGE"},
+}
+
+// mediaError answers with the status matching an error of the media service
+func mediaError(c *gin.Context, log *slog.Logger, err error) {
+	for _, e := range uploadErrors {
+		if errors.Is(err, e.err) {
+			log.Info(err.Error())
+			c.JSON(e.status, gin.H{"error": err.Error(), "code": e.code})
+			return
+		}
+	}
+
+	switch {
+	case errors.Is(err, services.ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrMediaNotFound.Error()})
+	case errors.Is(err, storage.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+	case errors.Is(err, services.ErrMediaForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": services.ErrMediaForbidden.Error()})
+	case status.Code(err) != codes.Unknown:
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+	default:
+		log.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
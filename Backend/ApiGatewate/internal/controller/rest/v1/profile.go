@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/common"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
+	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary     Get Me
+// @Description Get the account and profile of the current user
+// @ID          GetMe
+// @Tags  	    Auth
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.GetMeResponse
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Failure     503
+// @Router      /auth/me [get]
+func (r *authRoutes) getMe(c *gin.Context) {
+	const op = "authRoutes.getMe"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	resp, err := r.s.GetMe(c.Request.Context(), &authv1.GetMeRequest{
+		AccessToken: c.GetString(_accessTokenKey),
+	})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Update Profile
+// @Description Update profile fields of the current user, omitted fields are kept and empty strings clear them.
+// @Description The avatar must be a URL returned by the media upload.
+// @ID          UpdateProfile
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.UpdateProfileRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.UpdateProfileResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Failure     503
+// @Router      /auth/me [patch]
+func (r *authRoutes) updateProfile(c *gin.Context) {
+	const op = "authRoutes.updateProfile"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.UpdateProfile(c.Request.Context(), req.ToGRPC(c.GetString(_accessTokenKey)))
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
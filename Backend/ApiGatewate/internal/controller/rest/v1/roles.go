@@ -0,0 +1,329 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/common"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
+	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type roleRoutes struct {
+	s   authv1.AuthServiceClient
+	log *slog.Logger
+}
+
+func NewRoleRoutes(log *slog.Logger, g Groups, s authv1.AuthServiceClient) {
+	r := &roleRoutes{
+		log: log,
+		s:   s,
+	}
+
+	admin := g.Admin.Group("/auth", requirePermission(log, s, permRolesManage))
+	{
+		admin.GET("/roles", r.listRoles)
+		admin.POST("/roles", r.createRole)
+		admin.DELETE("/roles/:title", r.deleteRole)
+		admin.POST("/roles/:title/permissions", r.grantPermission)
+		admin.DELETE("/roles/:title/permissions/:permission", r.revokePermission)
+
+		admin.GET("/permissions", r.listPermissions)
+		admin.POST("/permissions", r.createPermission)
+		admin.DELETE("/permissions/:title", r.deletePermission)
+	}
+}
+
+// @Summary     List Roles
+// @Description List roles with their permissions
+// @ID          ListRoles
+// @Tags  	    Roles
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.ListRolesResponse
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Failure     503
+// @Router      /auth/roles [get]
+func (r *roleRoutes) listRoles(c *gin.Context) {
+	const op = "roleRoutes.listRoles"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	resp, err := r.s.ListRoles(c.Request.Context(), &authv1.ListRolesRequest{})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Create Role
+// @Description Create a role without permissions
+// @ID          CreateRole
+// @Tags  	    Roles
+// @Accept      json
+// @Param 		request body entities.CreateRoleRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.CreateRoleResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Failure     503
+// @Router      /auth/roles [post]
+func (r *roleRoutes) createRole(c *gin.Context) {
+	const op = "roleRoutes.createRole"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.CreateRole(c.Request.Context(), req.ToGRPC())
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Delete Role
+// @Description Delete a role that is not assigned to any user
+// @ID          DeleteRole
+// @Tags  	    Roles
+// @Param       title path string true "role title"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.DeleteRoleResponse
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     409
+// @Failure     500
+// @Failure     503
+// @Router      /auth/roles/{title} [delete]
+func (r *roleRoutes) deleteRole(c *gin.Context) {
+	const op = "roleRoutes.deleteRole"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	req := &authv1.DeleteRoleRequest{
+		Title: c.Param("title"),
+	}
+
+	resp, err := r.s.DeleteRole(c.Request.Context(), req)
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Grant Permission
+// @Description Grant a permission to a role
+// @ID          GrantPermission
+// @Tags  	    Roles
+// @Accept      json
+// @Param       title path string true "role title"
+// @Param 		request body entities.GrantPermissionRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.GrantPermissionResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /auth/roles/{title}/permissions [post]
+func (r *roleRoutes) grantPermission(c *gin.Context) {
+	const op = "roleRoutes.grantPermission"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.GrantPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.GrantPermission(c.Request.Context(), req.ToGRPC(c.Param("title")))
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Revoke Permission
+// @Description Revoke a permission from a role
+// @ID          RevokePermission
+// @Tags  	    Roles
+// @Param       title path string true "role title"
+// @Param       permission path string true "permission title"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.RevokePermissionResponse
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /auth/roles/{title}/permissions/{permission} [delete]
+func (r *roleRoutes) revokePermission(c *gin.Context) {
+	const op = "roleRoutes.revokePermission"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	req := &authv1.RevokePermissionRequest{
+		Role:       c.Param("title"),
+		Permission: c.Param("permission"),
+	}
+
+	resp, err := r.s.RevokePermission(c.Request.Context(), req)
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     List Permissions
+// @Description List every known permission
+// @ID          ListPermissions
+// @Tags  	    Roles
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.ListPermissionsResponse
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Failure     503
+// @Router      /auth/permissions [get]
+func (r *roleRoutes) listPermissions(c *gin.Context) {
+	const op = "roleRoutes.listPermissions"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	resp, err := r.s.ListPermissions(c.Request.Context(), &authv1.ListPermissionsRequest{})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Create Permission
+// @Description Create a permission that can be granted to roles
+// @ID          CreatePermission
+// @Tags  	    Roles
+// @Accept      json
+// @Param 		request body entities.CreatePermissionRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.CreatePermissionResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Failure     503
+// @Router      /auth/permissions [post]
+func (r *roleRoutes) createPermission(c *gin.Context) {
+	const op = "roleRoutes.createPermission"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.CreatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.CreatePermission(c.Request.Context(), req.ToGRPC())
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Delete Permission
+// @Description Delete a permission and revoke it from every role
+// @ID          DeletePermission
+// @Tags  	    Roles
+// @Param       title path string true "permission title"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.DeletePermissionResponse
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /auth/permissions/{title} [delete]
+func (r *roleRoutes) deletePermission(c *gin.Context) {
+	const op = "roleRoutes.deletePermission"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	req := &authv1.DeletePermissionRequest{
+		Title: c.Param("title"),
+	}
+
+	resp, err := r.s.DeletePermission(c.Request.Context(), req)
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
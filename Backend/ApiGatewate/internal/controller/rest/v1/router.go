@@ -6,7 +6,10 @@ import (
 	"net/http"
 
 	_ "github.com/Homyakadze14/PsyhoApp/ApiGatewate/docs"
-	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/s3"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/jwt"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/tokencache"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/services"
 
 	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
 	"github.com/gin-contrib/cors"
@@ -20,6 +23,21 @@ type Clients struct {
 	Auth authv1.AuthServiceClient
 }
 
+// Groups splits the routes by the callers they admit
+type Groups struct {
+	// Public routes need no credentials
+	Public *gin.RouterGroup
+	// User routes require a bearer access token
+	User *gin.RouterGroup
+	// Enrollment routes require a bearer access token that may be pending 2FA enrollment,
+	// the handlers leave its check to the auth service
+	Enrollment *gin.RouterGroup
+	// Admin routes require a bearer access token, each route checks its own permission
+	Admin *gin.RouterGroup
+	// Internal routes require a service token in the X-Service-Token header
+	Internal *gin.RouterGroup
+}
+
 // Swagger spec:
 // @title       API Gatewate
 // @description API Gatewate
@@ -30,15 +48,24 @@ type Clients struct {
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
-func NewRouter(handler *gin.Engine, c Clients, log *slog.Logger, s3 *s3.S3Storage) {
+// @securityDefinitions.apikey ServiceTokenAuth
+// @in header
+// @name X-Service-Token
+//
+// Internal routes are served by internal, which may be handler itself.
+func NewRouter(handler, internal *gin.Engine, c Clients, log *slog.Logger, media *services.MediaService, st storage.Storage, v *jwt.Verifier, cache *tokencache.Cache) {
 	// Options
 	handler.Use(gin.Logger())
 	handler.Use(gin.Recovery())
+	if internal != handler {
+		internal.Use(gin.Logger())
+		internal.Use(gin.Recovery())
+	}
 
 	// Set cors
 	corsConf := cors.DefaultConfig()
 	corsConf.AllowOrigins = []string{"http://localhost:5173", "http://147.45.235.14:5173"}
-	corsConf.AllowHeaders = []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "accept", "origin", "Cache-Control", "X-Requested-With"}
+	corsConf.AllowHeaders = []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "accept", "origin", "Cache-Control", "X-Requested-With", "X-Amz-Checksum-Sha256"}
 	corsConf.AllowCredentials = true
 	handler.Use(cors.New(corsConf))
 
@@ -48,14 +75,32 @@ func NewRouter(handler *gin.Engine, c Clients, log *slog.Logger, s3 *s3.S3Storag
 
 	// K8s probe
 	handler.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	if internal != handler {
+		internal.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
 
 	// Prometheus metrics
 	handler.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// Public keys for access token verification
+	NewWellKnownRoutes(log, handler, c.Auth, v)
+
 	// Routers
-	g := handler.Group("/api/v1")
+	auth := authMiddleware(log, c.Auth, v, cache)
+	g := Groups{
+		Public:     handler.Group("/api/v1"),
+		User:       handler.Group("/api/v1", auth),
+		Enrollment: handler.Group("/api/v1", bearerMiddleware()),
+		Admin:      handler.Group("/api/v1", auth),
+		Internal:   internal.Group("/api/v1/internal", serviceMiddleware(log, c.Auth)),
+	}
 	{
-		NewAuthRoutes(log, g, c.Auth)
-		NewMediaRoutes(log, g, s3)
+		NewAuthRoutes(log, g, c.Auth, cache)
+		NewRoleRoutes(log, g, c.Auth)
+		NewAdminRoutes(log, g, c.Auth, cache)
+		NewMediaRoutes(log, g, media)
+		if signed, ok := st.(storage.SignedStorage); ok {
+			NewFileRoutes(log, g, signed)
+		}
 	}
 }
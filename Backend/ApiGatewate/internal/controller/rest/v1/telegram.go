@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/common"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
+	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary     Get Telegram Connection
+// @Description Get the Telegram account linked to the current user
+// @ID          GetTelegramConnection
+// @Tags  	    Auth
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.GetTelegramConnectionResponse
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /auth/telegram [get]
+func (r *authRoutes) getTelegramConnection(c *gin.Context) {
+	const op = "authRoutes.getTelegramConnection"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	resp, err := r.s.GetTelegramConnection(c.Request.Context(), &authv1.GetTelegramConnectionRequest{
+		AccessToken: c.GetString(_accessTokenKey),
+	})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Unlink Telegram
+// @Description Unlink the Telegram account of the current user
+// @ID          UnlinkTelegram
+// @Tags  	    Auth
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.UnlinkTelegramResponse
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /auth/telegram [delete]
+func (r *authRoutes) unlinkTelegram(c *gin.Context) {
+	const op = "authRoutes.unlinkTelegram"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	resp, err := r.s.UnlinkTelegram(c.Request.Context(), &authv1.UnlinkTelegramRequest{
+		AccessToken: c.GetString(_accessTokenKey),
+	})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Relink Telegram
+// @Description Link the Telegram account that requested the verification code to the current user
+// @ID          RelinkTelegram
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.RelinkTelegramRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.RelinkTelegramResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     429
+// @Failure     500
+// @Failure     503
+// @Router      /auth/telegram/relink [post]
+func (r *authRoutes) relinkTelegram(c *gin.Context) {
+	const op = "authRoutes.relinkTelegram"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.RelinkTelegramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.RelinkTelegram(c.Request.Context(), req.ToGRPC(c.GetString(_accessTokenKey)))
+	if err != nil {
+		setRetryAfter(c, err)
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Resolve Telegram User
+// @Description Get the user linked to a Telegram account
+// @ID          ResolveTelegramUser
+// @Tags  	    Internal
+// @Accept      json
+// @Param 		request body entities.ResolveTelegramUserRequest false "request"
+// @Produce     json
+// @Security    ServiceTokenAuth
+// @Success     200 {object} authv1.ResolveTelegramUserResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Failure     503
+// @Router      /internal/auth/telegram/resolve [post]
+func (r *authRoutes) resolveTelegramUser(c *gin.Context) {
+	const op = "authRoutes.resolveTelegramUser"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.ResolveTelegramUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.ResolveTelegramUser(c.Request.Context(), req.ToGRPC(c.GetString(_serviceTokenKey)))
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
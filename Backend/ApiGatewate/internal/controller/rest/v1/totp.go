@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/common"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
+	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary     Login Verify TOTP
+// @Description Complete a login challenge with a two-factor code or a recovery code
+// @ID          LoginVerifyTOTP
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.LoginVerifyTOTPRequest false "request"
+// @Produce     json
+// @Success     200 {object} authv1.LoginResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     429
+// @Failure     500
+// @Failure     503
+// @Router      /auth/login/totp [post]
+func (r *authRoutes) loginVerifyTOTP(c *gin.Context) {
+	const op = "authRoutes.loginVerifyTOTP"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.LoginVerifyTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.LoginVerifyTOTP(c.Request.Context(), req.ToGRPC())
+	if err != nil {
+		setRetryAfter(c, err)
+		code, httpErr := common.GetProtoErrWithStatusCode(err)
+		log.Error(httpErr.Error())
+		c.JSON(code, protoErrorBody(err, httpErr))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Enroll TOTP
+// @Description Start two-factor enrollment, the secret and recovery codes are shown only once
+// @ID          EnrollTOTP
+// @Tags  	    Auth
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.EnrollTOTPResponse
+// @Failure     401
+// @Failure     403
+// @Failure     409
+// @Failure     500
+// @Failure     503
+// @Router      /auth/totp/enroll [post]
+func (r *authRoutes) enrollTOTP(c *gin.Context) {
+	const op = "authRoutes.enrollTOTP"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	resp, err := r.s.EnrollTOTP(c.Request.Context(), &authv1.EnrollTOTPRequest{
+		AccessToken: c.GetString(_accessTokenKey),
+	})
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Confirm TOTP
+// @Description Enable two-factor authentication with the first code of the authenticator app.
+// @Description Other sessions are revoked, a session that was pending enrollment gets full tokens on refresh.
+// @ID          ConfirmTOTP
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.TOTPCodeRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.ConfirmTOTPResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     409
+// @Failure     429
+// @Failure     500
+// @Failure     503
+// @Router      /auth/totp/confirm [post]
+func (r *authRoutes) confirmTOTP(c *gin.Context) {
+	const op = "authRoutes.confirmTOTP"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.ConfirmTOTP(c.Request.Context(), req.ToConfirmGRPC(c.GetString(_accessTokenKey)))
+	if err != nil {
+		setRetryAfter(c, err)
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Disable TOTP
+// @Description Turn two-factor authentication off with a code or a recovery code
+// @ID          DisableTOTP
+// @Tags  	    Auth
+// @Accept      json
+// @Param 		request body entities.TOTPCodeRequest false "request"
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} authv1.DisableTOTPResponse
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     409
+// @Failure     429
+// @Failure     500
+// @Failure     503
+// @Router      /auth/totp/disable [post]
+func (r *authRoutes) disableTOTP(c *gin.Context) {
+	const op = "authRoutes.disableTOTP"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var req *entities.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": common.GetErrMessages(err).Error()})
+		return
+	}
+
+	resp, err := r.s.DisableTOTP(c.Request.Context(), req.ToDisableGRPC(c.GetString(_accessTokenKey)))
+	if err != nil {
+		setRetryAfter(c, err)
+		code, err := common.GetProtoErrWithStatusCode(err)
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
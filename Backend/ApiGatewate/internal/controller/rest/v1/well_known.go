@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/common"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/jwt"
+	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type wellKnownRoutes struct {
+	s   authv1.AuthServiceClient
+	v   *jwt.Verifier
+	log *slog.Logger
+}
+
+func NewWellKnownRoutes(log *slog.Logger, handler *gin.Engine, s authv1.AuthServiceClient, v *jwt.Verifier) {
+	r := &wellKnownRoutes{
+		log: log,
+		s:   s,
+		v:   v,
+	}
+
+	g := handler.Group("/.well-known")
+	{
+		g.GET("/jwks.json", r.jwks)
+	}
+}
+
+// jwks serves the public keys used to verify signed access tokens
+func (r *wellKnownRoutes) jwks(c *gin.Context) {
+	const op = "wellKnownRoutes.jwks"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var (
+		resp *authv1.GetJWKSResponse
+		err  error
+	)
+	if r.v != nil {
+		resp, err = r.v.Keys(c.Request.Context())
+	} else {
+		resp, err = r.s.GetJWKS(c.Request.Context(), &authv1.GetJWKSRequest{})
+	}
+	if err != nil {
+		code, err := common.GetProtoErrWithStatusCode(err)
+		if code == 0 {
+			code = http.StatusInternalServerError
+		}
+		log.Error(err.Error())
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	if resp.Keys == nil {
+		resp.Keys = []*authv1.JWK{}
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, resp)
+}
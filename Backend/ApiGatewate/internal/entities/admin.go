@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"time"
+
+	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
+)
+
+type ListUsersQuery struct {
+	Cursor         string    `form:"cursor"`
+	Limit          int32     `form:"limit" binding:"omitempty,min=1,max=100"`
+	Role           string    `form:"role"`
+	UsernamePrefix string    `form:"username_prefix"`
+	CreatedFrom    time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo      time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Status         string    `form:"status" binding:"omitempty,oneof=pending active blocked deleted"`
+}
+
+func (r *ListUsersQuery) ToGRPC() *authv1.ListUsersRequest {
+	req := &authv1.ListUsersRequest{
+		Cursor:         r.Cursor,
+		Limit:          r.Limit,
+		Role:           r.Role,
+		UsernamePrefix: r.UsernamePrefix,
+		Status:         r.Status,
+	}
+	if !r.CreatedFrom.IsZero() {
+		req.CreatedFrom = r.CreatedFrom.Unix()
+	}
+	if !r.CreatedTo.IsZero() {
+		req.CreatedTo = r.CreatedTo.Unix()
+	}
+	return req
+}
+
+type GenerateServiceTokenRequest struct {
+	ServiceName string   `json:"service_name" binding:"required,max=250"`
+	Scopes      []string `json:"scopes"`
+	// TTLSeconds of zero issues a token that never expires
+	TTLSeconds int64 `json:"ttl_seconds" binding:"min=0"`
+}
+
+func (r *GenerateServiceTokenRequest) ToGRPC() *authv1.GenerateServiceTokenRequest {
+	return &authv1.GenerateServiceTokenRequest{
+		ServiceName: r.ServiceName,
+		Scopes:      r.Scopes,
+		TtlSeconds:  r.TTLSeconds,
+	}
+}
+
+type RotateServiceTokenRequest struct {
+	ServiceName string `json:"service_name" binding:"required"`
+	// OverlapSeconds of zero uses the configured overlap
+	OverlapSeconds int64 `json:"overlap_seconds" binding:"min=0"`
+	// TTLSeconds of zero keeps the lifetime of the current token
+	TTLSeconds int64 `json:"ttl_seconds" binding:"min=0"`
+}
+
+func (r *RotateServiceTokenRequest) ToGRPC() *authv1.RotateServiceTokenRequest {
+	return &authv1.RotateServiceTokenRequest{
+		ServiceName:    r.ServiceName,
+		OverlapSeconds: r.OverlapSeconds,
+		TtlSeconds:     r.TTLSeconds,
+	}
+}
@@ -19,19 +19,234 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required,min=8,max=50"`
 }
 
-func (r *LoginRequest) ToGRPC() *authv1.LoginRequest {
+func (r *LoginRequest) ToGRPC(userAgent, ip string) *authv1.LoginRequest {
 	return &authv1.LoginRequest{
+		Username:  r.Username,
+		Password:  r.Password,
+		UserAgent: userAgent,
+		Ip:        ip,
+	}
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (r *RefreshRequest) ToGRPC(userAgent, ip string) *authv1.RefreshRequest {
+	return &authv1.RefreshRequest{
+		RefreshToken: r.RefreshToken,
+		UserAgent:    userAgent,
+		Ip:           ip,
+	}
+}
+
+type ChangePasswordRequest struct {
+	OldPassword         string `json:"old_password" binding:"required"`
+	NewPassword         string `json:"new_password" binding:"required,min=8,max=50"`
+	RevokeOtherSessions bool   `json:"revoke_other_sessions"`
+}
+
+func (r *ChangePasswordRequest) ToGRPC(accessToken string) *authv1.ChangePasswordRequest {
+	return &authv1.ChangePasswordRequest{
+		AccessToken:         accessToken,
+		OldPassword:         r.OldPassword,
+		NewPassword:         r.NewPassword,
+		RevokeOtherSessions: r.RevokeOtherSessions,
+	}
+}
+
+type RequestPasswordResetRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+func (r *RequestPasswordResetRequest) ToGRPC() *authv1.RequestPasswordResetRequest {
+	return &authv1.RequestPasswordResetRequest{
 		Username: r.Username,
-		Password: r.Password,
 	}
 }
 
-type LogoutRequest struct {
-	AccessToken string `json:"access_token" binding:"required"`
+type ConfirmPasswordResetRequest struct {
+	Username    string `json:"username" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=50"`
+}
+
+func (r *ConfirmPasswordResetRequest) ToGRPC() *authv1.ConfirmPasswordResetRequest {
+	return &authv1.ConfirmPasswordResetRequest{
+		Username:    r.Username,
+		Code:        r.Code,
+		NewPassword: r.NewPassword,
+	}
+}
+
+type ActivateAccountRequest struct {
+	Username string `json:"username" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+func (r *ActivateAccountRequest) ToGRPC() *authv1.ActivateAccountRequest {
+	return &authv1.ActivateAccountRequest{
+		Username: r.Username,
+		Code:     r.Code,
+	}
+}
+
+type ResendActivationCodeRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+func (r *ResendActivationCodeRequest) ToGRPC() *authv1.ResendActivationCodeRequest {
+	return &authv1.ResendActivationCodeRequest{
+		Username: r.Username,
+	}
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (r *TOTPCodeRequest) ToConfirmGRPC(accessToken string) *authv1.ConfirmTOTPRequest {
+	return &authv1.ConfirmTOTPRequest{
+		AccessToken: accessToken,
+		Code:        r.Code,
+	}
+}
+
+func (r *TOTPCodeRequest) ToDisableGRPC(accessToken string) *authv1.DisableTOTPRequest {
+	return &authv1.DisableTOTPRequest{
+		AccessToken: accessToken,
+		Code:        r.Code,
+	}
+}
+
+type LoginVerifyTOTPRequest struct {
+	ChallengeID string `json:"challenge_id" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+}
+
+func (r *LoginVerifyTOTPRequest) ToGRPC() *authv1.LoginVerifyTOTPRequest {
+	return &authv1.LoginVerifyTOTPRequest{
+		ChallengeId: r.ChallengeID,
+		Code:        r.Code,
+	}
+}
+
+// TelegramLoginRequest is the payload passed by the Telegram Login Widget
+type TelegramLoginRequest struct {
+	ID        int64  `json:"id" binding:"required"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+	PhotoURL  string `json:"photo_url"`
+	AuthDate  int64  `json:"auth_date" binding:"required"`
+	Hash      string `json:"hash" binding:"required"`
+}
+
+func (r *TelegramLoginRequest) ToGRPC(userAgent, ip string) *authv1.LoginWithTelegramRequest {
+	return &authv1.LoginWithTelegramRequest{
+		Id:        r.ID,
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		Username:  r.Username,
+		PhotoUrl:  r.PhotoURL,
+		AuthDate:  r.AuthDate,
+		Hash:      r.Hash,
+		UserAgent: userAgent,
+		Ip:        ip,
+	}
+}
+
+type RelinkTelegramRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (r *RelinkTelegramRequest) ToGRPC(accessToken string) *authv1.RelinkTelegramRequest {
+	return &authv1.RelinkTelegramRequest{
+		AccessToken: accessToken,
+		Code:        r.Code,
+	}
+}
+
+type ResolveTelegramUserRequest struct {
+	TgUserID int64 `json:"tg_user_id" binding:"required"`
+}
+
+func (r *ResolveTelegramUserRequest) ToGRPC(serviceToken string) *authv1.ResolveTelegramUserRequest {
+	return &authv1.ResolveTelegramUserRequest{
+		ServiceToken: serviceToken,
+		TgUserId:     r.TgUserID,
+	}
+}
+
+type GenerateAuthCodeRequest struct {
+	TgUserID int64 `json:"tg_user_id" binding:"required"`
+}
+
+func (r *GenerateAuthCodeRequest) ToGRPC(serviceToken string) *authv1.GenerateAuthCodeRequest {
+	return &authv1.GenerateAuthCodeRequest{
+		ServiceToken: serviceToken,
+		TgUserId:     r.TgUserID,
+	}
+}
+
+type VerifyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (r *VerifyRequest) ToGRPC(accessToken string) *authv1.VerifyRequest {
+	return &authv1.VerifyRequest{
+		AccessToken: accessToken,
+		Code:        r.Code,
+	}
+}
+
+type CreateRoleRequest struct {
+	Title string `json:"title" binding:"required,max=250"`
+}
+
+func (r *CreateRoleRequest) ToGRPC() *authv1.CreateRoleRequest {
+	return &authv1.CreateRoleRequest{
+		Title: r.Title,
+	}
+}
+
+type CreatePermissionRequest struct {
+	Title       string `json:"title" binding:"required,max=250"`
+	Description string `json:"description" binding:"max=512"`
+}
+
+func (r *CreatePermissionRequest) ToGRPC() *authv1.CreatePermissionRequest {
+	return &authv1.CreatePermissionRequest{
+		Title:       r.Title,
+		Description: r.Description,
+	}
+}
+
+type GrantPermissionRequest struct {
+	Permission string `json:"permission" binding:"required"`
+}
+
+func (r *GrantPermissionRequest) ToGRPC(role string) *authv1.GrantPermissionRequest {
+	return &authv1.GrantPermissionRequest{
+		Role:       role,
+		Permission: r.Permission,
+	}
+}
+
+// UpdateProfileRequest changes the fields that are present, an empty string clears a field
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,max=2048"`
+	Timezone    *string `json:"timezone" binding:"omitempty,max=64"`
+	Locale      *string `json:"locale" binding:"omitempty,max=35"`
 }
 
-func (r *LogoutRequest) ToGRPC() *authv1.LogoutRequest {
-	return &authv1.LogoutRequest{
-		AccessToken: r.AccessToken,
+func (r *UpdateProfileRequest) ToGRPC(accessToken string) *authv1.UpdateProfileRequest {
+	return &authv1.UpdateProfileRequest{
+		AccessToken: accessToken,
+		DisplayName: r.DisplayName,
+		AvatarUrl:   r.AvatarURL,
+		Timezone:    r.Timezone,
+		Locale:      r.Locale,
 	}
 }
@@ -1,17 +1,118 @@
 package entities
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 type UploadResponse struct {
 	Files []FileResp
 }
 
 type File struct {
-	Filename string
-	File     io.ReadSeeker
+	Filename    string
+	ContentType string
+	Size        int64
+	File        io.ReadSeeker
 }
 
 type FileResp struct {
-	Filename string
-	URL      string
+	ID         string
+	Filename   string
+	URL        string
+	Thumbnails []Thumbnail
+}
+
+// Thumbnail is a scaled down copy of an image with the URL to fetch it
+type Thumbnail struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// Media visibility, public objects are addressable by their plain URL
+const (
+	MediaPublic  = "public"
+	MediaPrivate = "private"
+)
+
+// Media status, presigned uploads stay pending until they are completed
+const (
+	MediaPending = "pending"
+	MediaReady   = "ready"
+)
+
+// Media is the stored metadata of an uploaded object.
+// Checksum is the hex SHA-256 of the content, empty when the client did not provide it for a presigned upload.
+type Media struct {
+	ID          string    `json:"id"`
+	OwnerID     int64     `json:"owner_id"`
+	Category    string    `json:"category"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	Bucket      string    `json:"-"`
+	Key         string    `json:"key"`
+	Visibility  string    `json:"visibility"`
+	Status      string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Variants are the thumbnails stored alongside a processed image
+	Variants []MediaVariant `json:"variants,omitempty"`
+}
+
+// MediaVariant is an object generated from a media object, it shares its visibility and lifetime
+type MediaVariant struct {
+	Name        string `json:"name"`
+	Key         string `json:"key"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+type MediaResponse struct {
+	Media
+	URL        string      `json:"url"`
+	ExpiresAt  int64       `json:"expires_at,omitempty"`
+	Thumbnails []Thumbnail `json:"thumbnails,omitempty"`
+}
+
+type ListMediaQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+type ListMediaResponse struct {
+	Media []Media `json:"media"`
+}
+
+type PresignUploadRequest struct {
+	Category    string `json:"category" binding:"required"`
+	Filename    string `json:"filename" binding:"required,max=255"`
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+	// ChecksumSHA256 is the base64 SHA-256 of the content, the storage rejects uploads that don't match it
+	ChecksumSHA256 string `json:"checksum_sha256" binding:"omitempty,base64"`
+}
+
+// PresignUploadResponse tells the client how to upload the file directly to the storage.
+// The request must be sent with Method to URL carrying exactly the listed Headers.
+type PresignUploadResponse struct {
+	ID        string            `json:"id"`
+	Key       string            `json:"key"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt int64             `json:"expires_at"`
+}
+
+type CompleteUploadRequest struct {
+	Key string `json:"key" binding:"required"`
+}
+
+type DownloadURLResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt int64  `json:"expires_at"`
 }
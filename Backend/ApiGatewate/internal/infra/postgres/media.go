@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/services"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+const _mediaColumns = `id, owner_id, category, filename, content_type, size, checksum, bucket, object_key, visibility, status, created_at, variants`
+
+type MediaRepository struct {
+	postgres.DBConnector
+}
+
+func NewMediaRepository(pg postgres.DBConnector) *MediaRepository {
+	return &MediaRepository{pg}
+}
+
+// Create stores the metadata of a new object
+func (r *MediaRepository) Create(ctx context.Context, m *entities.Media) error {
+	const op = "repositories.MediaRepository.Create"
+
+	query := `
+		INSERT INTO media(` + _mediaColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := r.Exec(ctx, query,
+		m.ID, m.OwnerID, m.Category, m.Filename, m.ContentType, m.Size, m.Checksum,
+		m.Bucket, m.Key, m.Visibility, m.Status, m.CreatedAt, variants(m),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves the metadata of an object
+func (r *MediaRepository) GetByID(ctx context.Context, id string) (*entities.Media, error) {
+	const op = "repositories.MediaRepository.GetByID"
+
+	query := `SELECT ` + _mediaColumns + ` FROM media WHERE id = $1`
+
+	m, err := scanMedia(r.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, services.ErrMediaNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return m, nil
+}
+
+// GetByKey retrieves the metadata of the object stored under the key
+func (r *MediaRepository) GetByKey(ctx context.Context, key string) (*entities.Media, error) {
+	const op = "repositories.MediaRepository.GetByKey"
+
+	query := `SELECT ` + _mediaColumns + ` FROM media WHERE object_key = $1`
+
+	m, err := scanMedia(r.QueryRow(ctx, query, key))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, services.ErrMediaNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return m, nil
+}
+
+// ListByOwner retrieves a page of ready objects of the user, newest first
+func (r *MediaRepository) ListByOwner(ctx context.Context, ownerID int64, limit, offset int) ([]entities.Media, error) {
+	const op = "repositories.MediaRepository.ListByOwner"
+
+	query := `
+		SELECT ` + _mediaColumns + `
+		FROM media
+		WHERE owner_id = $1 AND status = $2
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.Query(ctx, query, ownerID, entities.MediaReady, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	media := make([]entities.Media, 0)
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		media = append(media, *m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return media, nil
+}
+
+// ListPendingBefore retrieves the oldest objects still pending since before the time
+func (r *MediaRepository) ListPendingBefore(ctx context.Context, before time.Time, limit int) ([]entities.Media, error) {
+	const op = "repositories.MediaRepository.ListPendingBefore"
+
+	query := `
+		SELECT ` + _mediaColumns + `
+		FROM media
+		WHERE status = $1 AND created_at < $2
+		ORDER BY created_at
+		LIMIT $3
+	`
+
+	rows, err := r.Query(ctx, query, entities.MediaPending, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	media := make([]entities.Media, 0)
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		media = append(media, *m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return media, nil
+}
+
+// Update stores the content, variants and status of an object that was processed after its upload
+func (r *MediaRepository) Update(ctx context.Context, m *entities.Media) error {
+	const op = "repositories.MediaRepository.Update"
+
+	query := `
+		UPDATE media
+		SET content_type = $2, size = $3, checksum = $4, status = $5, variants = $6
+		WHERE id = $1
+	`
+
+	n, err := r.Exec(ctx, query, m.ID, m.ContentType, m.Size, m.Checksum, m.Status, variants(m))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return services.ErrMediaNotFound
+	}
+
+	return nil
+}
+
+// Delete removes the metadata of an object
+func (r *MediaRepository) Delete(ctx context.Context, id string) error {
+	const op = "repositories.MediaRepository.Delete"
+
+	query := `DELETE FROM media WHERE id = $1`
+
+	n, err := r.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return services.ErrMediaNotFound
+	}
+
+	return nil
+}
+
+// DeletePending removes the metadata of an object that is still pending,
+// it reports false when the object was completed or removed meanwhile
+func (r *MediaRepository) DeletePending(ctx context.Context, id string) (bool, error) {
+	const op = "repositories.MediaRepository.DeletePending"
+
+	query := `DELETE FROM media WHERE id = $1 AND status = $2`
+
+	n, err := r.Exec(ctx, query, id, entities.MediaPending)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n > 0, nil
+}
+
+func scanMedia(row pgx.Row) (*entities.Media, error) {
+	var m entities.Media
+	err := row.Scan(
+		&m.ID, &m.OwnerID, &m.Category, &m.Filename, &m.ContentType, &m.Size, &m.Checksum,
+		&m.Bucket, &m.Key, &m.Visibility, &m.Status, &m.CreatedAt, &m.Variants,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// variants returns the variants of the object for the jsonb column, which holds an empty array rather than null
+func variants(m *entities.Media) []entities.MediaVariant {
+	if m.Variants == nil {
+		return []entities.MediaVariant{}
+	}
+	return m.Variants
+}
@@ -0,0 +1,74 @@
+package identity
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys forwarded to backend services.
+// The signature is a hex HMAC-SHA256 of "user_id\nrole\ntimestamp" with the shared secret.
+const (
+	MDUserID    = "x-user-id"
+	MDUserRole  = "x-user-role"
+	MDTimestamp = "x-user-ts"
+	MDSignature = "x-user-signature"
+)
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	UserID int64
+	Role   string
+}
+
+type ctxKey struct{}
+
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(ctxKey{}).(Identity)
+	return id, ok
+}
+
+// Signer attaches the identity of the context to outgoing gRPC calls
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Sign returns the signature of the identity issued at the unix timestamp
+func (s *Signer) Sign(id Identity, timestamp int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strconv.FormatInt(id.UserID, 10) + "\n" + id.Role + "\n" + strconv.FormatInt(timestamp, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// UnaryClientInterceptor forwards the identity as signed metadata.
+// Calls made outside of an authenticated request are sent unchanged.
+func (s *Signer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		id, ok := FromContext(ctx)
+		if ok {
+			ts := time.Now().Unix()
+			ctx = metadata.AppendToOutgoingContext(ctx,
+				MDUserID, strconv.FormatInt(id.UserID, 10),
+				MDUserRole, id.Role,
+				MDTimestamp, strconv.FormatInt(ts, 10),
+				MDSignature, s.Sign(id, ts),
+			)
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,269 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrInvalidImage  = errors.New("image can't be decoded")
+	ErrImageTooLarge = errors.New("image has too many pixels")
+	ErrClosed        = errors.New("image processor is closed")
+)
+
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeWebP = "image/webp"
+)
+
+// Variant is an encoded image, Name is empty for the processed original
+type Variant struct {
+	Name        string
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// Result holds the processed original and its thumbnails, smallest first
+type Result struct {
+	Original   Variant
+	Thumbnails []Variant
+}
+
+type job struct {
+	ctx  context.Context
+	r    io.Reader
+	done chan<- result
+}
+
+type result struct {
+	res *Result
+	err error
+}
+
+// Processor decodes uploaded images, strips their metadata and generates thumbnails.
+// Images are processed by a fixed number of workers shared by all uploads.
+type Processor struct {
+	sizes        []int
+	maxDimension int
+	maxPixels    int
+	quality      int
+
+	jobs   chan job
+	closed chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+}
+
+func New(cfg config.ImagesConfig) *Processor {
+	workers := max(cfg.Workers, 1)
+
+	sizes := slices.Clone(cfg.ThumbnailSizes)
+	slices.Sort(sizes)
+	sizes = slices.Compact(sizes)
+
+	p := &Processor{
+		sizes:        sizes,
+		maxDimension: cfg.MaxDimension,
+		maxPixels:    cfg.MaxPixels,
+		quality:      cfg.JPEGQuality,
+		jobs:         make(chan job),
+		closed:       make(chan struct{}),
+	}
+
+	p.wg.Add(workers)
+	for range workers {
+		go p.work()
+	}
+
+	return p
+}
+
+// Supports reports whether images of the content type can be processed
+func Supports(contentType string) bool {
+	switch contentType {
+	case TypeJPEG, TypePNG, TypeWebP:
+		return true
+	}
+	return false
+}
+
+// Process waits for a free worker and processes the image read from r
+func (p *Processor) Process(ctx context.Context, r io.Reader) (*Result, error) {
+	const op = "Processor.Process"
+
+	done := make(chan result, 1)
+
+	select {
+	case p.jobs <- job{ctx: ctx, r: r, done: done}:
+	case <-p.closed:
+		return nil, fmt.Errorf("%s: %w", op, ErrClosed)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+
+	select {
+	case out := <-done:
+		if out.err != nil {
+			return nil, fmt.Errorf("%s: %w", op, out.err)
+		}
+		return out.res, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+}
+
+// Close stops the workers once the images being processed are done
+func (p *Processor) Close() {
+	p.once.Do(func() {
+		close(p.closed)
+	})
+	p.wg.Wait()
+}
+
+func (p *Processor) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case j := <-p.jobs:
+			if err := j.ctx.Err(); err != nil {
+				j.done <- result{err: err}
+				continue
+			}
+			res, err := p.process(j.r)
+			j.done <- result{res: res, err: err}
+		case <-p.closed:
+			return
+		}
+	}
+}
+
+// process re-encodes the image upright without its metadata, scaling down originals larger than maxDimension.
+// JPEG and PNG keep their format, WebP is stored as JPEG or as PNG when it has transparency.
+func (p *Processor) process(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImage, err)
+	}
+	if p.maxPixels > 0 && cfg.Width*cfg.Height > p.maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImage, err)
+	}
+
+	switch format {
+	case "jpeg":
+		img = orient(img, jpegOrientation(data))
+	case "webp":
+		img = orient(img, webpOrientation(data))
+	}
+
+	if p.maxDimension > 0 {
+		img = fit(img, p.maxDimension)
+	}
+
+	contentType := TypeJPEG
+	if format == "png" || !opaque(img) {
+		contentType = TypePNG
+	}
+
+	original, err := p.encode("", img, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Original: original}
+
+	b := img.Bounds()
+	for _, size := range p.sizes {
+		// A thumbnail as large as the image would only be a copy of it
+		if size <= 0 || max(b.Dx(), b.Dy()) <= size {
+			continue
+		}
+
+		thumb := fit(img, size)
+		thumbType := TypeJPEG
+		if !opaque(thumb) {
+			thumbType = TypePNG
+		}
+
+		v, err := p.encode(fmt.Sprintf("thumb_%d", size), thumb, thumbType)
+		if err != nil {
+			return nil, err
+		}
+		res.Thumbnails = append(res.Thumbnails, v)
+	}
+
+	return res, nil
+}
+
+func (p *Processor) encode(name string, img image.Image, contentType string) (Variant, error) {
+	var buf bytes.Buffer
+
+	var err error
+	if contentType == TypePNG {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.quality})
+	}
+	if err != nil {
+		return Variant{}, err
+	}
+
+	b := img.Bounds()
+	return Variant{
+		Name:        name,
+		Width:       b.Dx(),
+		Height:      b.Dy(),
+		ContentType: contentType,
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// fit scales the image down so that its larger side is at most size, keeping the aspect ratio
+func fit(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	if w >= h {
+		w, h = size, max(h*size/w, 1)
+	} else {
+		w, h = max(w*size/h, 1), size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+
+	return dst
+}
+
+func opaque(img image.Image) bool {
+	o, ok := img.(interface{ Opaque() bool })
+	return ok && o.Opaque()
+}
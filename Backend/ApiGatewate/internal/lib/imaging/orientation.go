@@ -0,0 +1,175 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+const _orientationTag = 0x0112
+
+var _exifHeader = []byte("Exif\x00\x00")
+
+// jpegOrientation returns the EXIF orientation of a JPEG, 1 when it has none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// Fill byte before a marker
+			i++
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// Metadata segments come before the scan
+			return 1
+		case marker >= 0xD0 && marker <= 0xD7 || marker == 0x01:
+			// Markers without a segment
+			i += 2
+			continue
+		}
+
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+n]
+		if marker == 0xE1 && bytes.HasPrefix(segment, _exifHeader) {
+			return tiffOrientation(segment[len(_exifHeader):])
+		}
+
+		i += 2 + n
+	}
+
+	return 1
+}
+
+// webpOrientation returns the orientation from the EXIF chunk of a WebP, 1 when it has none
+func webpOrientation(data []byte) int {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 1
+	}
+
+	for i := 12; i+8 <= len(data); {
+		// The size wraps around to negative on 32-bit platforms
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		body := i + 8
+		if size < 0 || size > len(data)-body {
+			return 1
+		}
+
+		if string(data[i:i+4]) == "EXIF" {
+			return tiffOrientation(bytes.TrimPrefix(data[body:body+size], _exifHeader))
+		}
+
+		// Chunks are padded to an even size
+		i = body + size + size&1
+	}
+
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of EXIF data
+func tiffOrientation(b []byte) int {
+	if len(b) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(b[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	if order.Uint16(b[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(b[4:]))
+	if ifd < 8 || ifd+2 > len(b) {
+		return 1
+	}
+
+	entries := int(order.Uint16(b[ifd:]))
+	for i := range entries {
+		e := ifd + 2 + i*12
+		if e+12 > len(b) {
+			return 1
+		}
+		if order.Uint16(b[e:]) != _orientationTag {
+			continue
+		}
+
+		// The orientation is a single SHORT stored in the value field
+		if order.Uint16(b[e+2:]) != 3 {
+			return 1
+		}
+		o := int(order.Uint16(b[e+8:]))
+		if o < 1 || o > 8 {
+			return 1
+		}
+		return o
+	}
+
+	return 1
+}
+
+// orient applies the EXIF orientation so the image is stored upright
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := range h {
+		for x := range w {
+			dx, dy := x, y
+			switch orientation {
+			case 2:
+				dx = w - 1 - x
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dy = h - 1 - y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+
+			s := src.PixOffset(x, y)
+			d := dst.PixOffset(dx, dy)
+			copy(dst.Pix[d:d+4], src.Pix[s:s+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,254 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+type ifdEntry struct {
+	tag, typ uint16
+	value    uint16
+}
+
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// tiff builds EXIF data with a single IFD holding the entries
+func tiff(order byteOrder, entries ...ifdEntry) []byte {
+	b := make([]byte, 8, 8+2+len(entries)*12+4)
+	if order == binary.LittleEndian {
+		copy(b, "II")
+	} else {
+		copy(b, "MM")
+	}
+	order.PutUint16(b[2:], 42)
+	order.PutUint32(b[4:], 8)
+
+	b = order.AppendUint16(b, uint16(len(entries)))
+	for _, e := range entries {
+		b = order.AppendUint16(b, e.tag)
+		b = order.AppendUint16(b, e.typ)
+		b = order.AppendUint32(b, 1)
+		b = order.AppendUint16(b, e.value)
+		b = order.AppendUint16(b, 0)
+	}
+	return order.AppendUint32(b, 0)
+}
+
+func orientationTIFF(o uint16) []byte {
+	return tiff(binary.LittleEndian, ifdEntry{tag: _orientationTag, typ: 3, value: o})
+}
+
+// segment builds a JPEG marker segment
+func segment(marker byte, body []byte) []byte {
+	b := []byte{0xFF, marker}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(body)+2))
+	return append(b, body...)
+}
+
+func jpegFile(segments ...[]byte) []byte {
+	b := []byte{0xFF, 0xD8}
+	for _, s := range segments {
+		b = append(b, s...)
+	}
+	return append(b, 0xFF, 0xD9)
+}
+
+func exifSegment(exif []byte) []byte {
+	return segment(0xE1, append([]byte("Exif\x00\x00"), exif...))
+}
+
+// chunk builds a RIFF chunk padded to an even size
+func chunk(id string, body []byte) []byte {
+	b := []byte(id)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(body)))
+	b = append(b, body...)
+	if len(body)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	var body []byte
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+
+	b := []byte("RIFF")
+	b = binary.LittleEndian.AppendUint32(b, uint32(4+len(body)))
+	b = append(b, "WEBP"...)
+	return append(b, body...)
+}
+
+func TestTIFFOrientation(t *testing.T) {
+	withIFD := func(offset uint32) []byte {
+		b := orientationTIFF(6)
+		binary.LittleEndian.PutUint32(b[4:], offset)
+		return b
+	}
+	withEntries := func(n uint16) []byte {
+		b := tiff(binary.LittleEndian, ifdEntry{tag: 0x010F, typ: 2, value: 0})
+		binary.LittleEndian.PutUint16(b[8:], n)
+		return b
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"little endian", orientationTIFF(6), 6},
+		{"big endian", tiff(binary.BigEndian, ifdEntry{tag: _orientationTag, typ: 3, value: 3}), 3},
+		{"after other tags", tiff(binary.LittleEndian,
+			ifdEntry{tag: 0x010F, typ: 2, value: 0},
+			ifdEntry{tag: _orientationTag, typ: 3, value: 8},
+		), 8},
+		{"no orientation tag", tiff(binary.LittleEndian, ifdEntry{tag: 0x010F, typ: 2, value: 0}), 1},
+		{"no entries", tiff(binary.LittleEndian), 1},
+		{"empty", nil, 1},
+		{"shorter than the header", []byte("II*\x00\x08\x00"), 1},
+		{"unknown byte order", append([]byte("XX"), orientationTIFF(6)[2:]...), 1},
+		{"bad magic", func() []byte { b := orientationTIFF(6); b[2] = 43; return b }(), 1},
+		{"IFD inside the header", withIFD(4), 1},
+		{"IFD past the end", withIFD(1 << 20), 1},
+		{"IFD at the max offset", withIFD(0xFFFFFFFF), 1},
+		{"IFD count cut off", withIFD(uint32(len(orientationTIFF(6)) - 1)), 1},
+		{"more entries than data", withEntries(0xFFFF), 1},
+		{"entries cut off", orientationTIFF(6)[:8+2+11], 1},
+		{"not a SHORT", tiff(binary.LittleEndian, ifdEntry{tag: _orientationTag, typ: 4, value: 6}), 1},
+		{"zero", orientationTIFF(0), 1},
+		{"above 8", orientationTIFF(9), 1},
+		{"max value", orientationTIFF(0xFFFF), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tiffOrientation(tt.data); got != tt.want {
+				t.Fatalf("tiffOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	exif := exifSegment(orientationTIFF(6))
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"exif", jpegFile(exif), 6},
+		{"after other segments", jpegFile(segment(0xE0, []byte("JFIF\x00")), segment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00")), exif), 6},
+		{"after fill bytes", append([]byte{0xFF, 0xD8, 0xFF, 0xFF}, jpegFile(exif)[2:]...), 6},
+		{"after a marker without segment", jpegFile([]byte{0xFF, 0xD0}, exif), 6},
+		{"no exif", jpegFile(segment(0xE0, []byte("JFIF\x00"))), 1},
+		{"exif after the scan", jpegFile(segment(0xDA, []byte{0, 0}), exif), 1},
+		{"not a jpeg", append([]byte{0x89, 'P'}, exif...), 1},
+		{"empty", nil, 1},
+		{"only the SOI", []byte{0xFF, 0xD8}, 1},
+		{"garbage instead of a marker", jpegFile([]byte{0x00, 0x00}, exif), 1},
+		{"segment length below 2", jpegFile([]byte{0xFF, 0xE1, 0x00, 0x01}, exif), 1},
+		{"segment length past the end", jpegFile([]byte{0xFF, 0xE1, 0xFF, 0xFF}), 1},
+		{"exif header only", jpegFile(exifSegment(nil)), 1},
+		{"broken tiff", jpegFile(exifSegment([]byte("MM\x00*\xFF\xFF\xFF\xFF"))), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Fatalf("jpegOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebPOrientation(t *testing.T) {
+	exif := orientationTIFF(6)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"exif", webpFile(chunk("VP8X", make([]byte, 10)), chunk("EXIF", exif)), 6},
+		{"exif with header", webpFile(chunk("EXIF", append([]byte("Exif\x00\x00"), exif...))), 6},
+		{"after an odd sized chunk", webpFile(chunk("ICCP", make([]byte, 3)), chunk("EXIF", exif)), 6},
+		{"no exif", webpFile(chunk("VP8 ", make([]byte, 10))), 1},
+		{"not a webp", append([]byte("RIFF\x00\x00\x00\x00WAVE"), chunk("EXIF", exif)...), 1},
+		{"empty", nil, 1},
+		{"chunk size past the end", webpFile([]byte("EXIF\x00\x01\x00\x00")), 1},
+		{"chunk size at the max", webpFile([]byte("EXIF\xFF\xFF\xFF\xFF"), exif), 1},
+		{"chunk header cut off", webpFile([]byte("EXIF\x10")), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := webpOrientation(tt.data); got != tt.want {
+				t.Fatalf("webpOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOrientationTruncated feeds every prefix of valid files, none may panic or find an orientation
+// the cut off data does not hold
+func TestOrientationTruncated(t *testing.T) {
+	j := jpegFile(segment(0xE0, []byte("JFIF\x00")), exifSegment(orientationTIFF(6)))
+	for n := range len(j) {
+		if got := jpegOrientation(j[:n]); got != 1 && n < len(j)-2 {
+			t.Fatalf("jpegOrientation of %d bytes = %d", n, got)
+		}
+	}
+
+	w := webpFile(chunk("ICCP", make([]byte, 3)), chunk("EXIF", orientationTIFF(6)))
+	for n := range len(w) {
+		if got := webpOrientation(w[:n]); got != 1 {
+			t.Fatalf("webpOrientation of %d bytes = %d", n, got)
+		}
+	}
+
+	b := orientationTIFF(6)
+	for n := range len(b) {
+		tiffOrientation(b[:n])
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3x2 image with a marked top left pixel
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	mark := color.RGBA{R: 255, A: 255}
+	img.Set(0, 0, mark)
+
+	tests := []struct {
+		orientation int
+		w, h        int
+		x, y        int
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+		{9, 3, 2, 0, 0},
+	}
+
+	for _, tt := range tests {
+		got := orient(img, tt.orientation)
+		b := got.Bounds()
+		if b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Fatalf("orientation %d: size %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+		}
+		if got.At(tt.x, tt.y) != mark {
+			t.Fatalf("orientation %d: marked pixel is not at %d,%d", tt.orientation, tt.x, tt.y)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
+)
+
+const (
+	_defaultFetchTimeout = 2 * time.Second
+	// _minRefetchInterval limits JWKS fetches triggered by unknown key IDs
+	_minRefetchInterval = 10 * time.Second
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidIssuer    = errors.New("invalid issuer")
+	ErrTokenExpired     = errors.New("token expired")
+)
+
+type Claims struct {
+	ID        string `json:"jti"`
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	Issuer    string `json:"iss"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	// TOTPPending is set on tokens only accepted to enroll two-factor authentication
+	TOTPPending bool `json:"totp_pending"`
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type publicKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// Verifier checks signed access tokens locally using the auth service JWKS
+type Verifier struct {
+	log    *slog.Logger
+	cfg    config.JWTConfig
+	client authv1.AuthServiceClient
+
+	mu        sync.RWMutex
+	jwks      *authv1.GetJWKSResponse
+	keys      map[string]publicKey
+	fetchedAt time.Time
+}
+
+func NewVerifier(log *slog.Logger, cfg config.JWTConfig, client authv1.AuthServiceClient) *Verifier {
+	return &Verifier{
+		log:    log,
+		cfg:    cfg,
+		client: client,
+		keys:   make(map[string]publicKey),
+	}
+}
+
+// RevocationCheck reports whether verified tokens must still be checked by the auth service
+func (v *Verifier) RevocationCheck() bool {
+	return v.cfg.RevocationCheck
+}
+
+// Keys returns the cached key set, refreshing it when stale
+func (v *Verifier) Keys(ctx context.Context) (*authv1.GetJWKSResponse, error) {
+	v.mu.RLock()
+	jwks, fetchedAt := v.jwks, v.fetchedAt
+	v.mu.RUnlock()
+
+	if jwks != nil && time.Since(fetchedAt) < v.cfg.JWKSRefreshInterval {
+		return jwks, nil
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		if jwks != nil {
+			return jwks, nil
+		}
+		return nil, err
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.jwks, nil
+}
+
+// Verify checks the token signature, issuer and expiry and returns its claims
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	k, err := v.key(ctx, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if h.Alg != k.alg {
+		return nil, ErrInvalidSignature
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if !verify(k, []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrInvalidSignature
+	}
+
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if claims.Issuer != v.cfg.Issuer {
+		return nil, ErrInvalidIssuer
+	}
+	if claims.ExpiresAt != 0 && time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+
+	return &claims, nil
+}
+
+// key looks up a public key by ID, fetching the JWKS when the key is unknown or stale
+func (v *Verifier) key(ctx context.Context, kid string) (publicKey, error) {
+	v.mu.RLock()
+	k, ok := v.keys[kid]
+	fetchedAt := v.fetchedAt
+	v.mu.RUnlock()
+
+	stale := time.Since(fetchedAt) >= v.cfg.JWKSRefreshInterval
+	if ok && !stale {
+		return k, nil
+	}
+
+	if stale || time.Since(fetchedAt) >= _minRefetchInterval {
+		if err := v.refresh(ctx); err != nil {
+			v.log.Error("failed to refresh jwks", slog.String("error", err.Error()))
+		}
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	k, ok = v.keys[kid]
+	if !ok {
+		return publicKey{}, ErrUnknownKey
+	}
+	return k, nil
+}
+
+func (v *Verifier) refresh(ctx context.Context) error {
+	const op = "jwt.Verifier.refresh"
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultFetchTimeout)
+	defer cancel()
+
+	jwks, err := v.client.GetJWKS(ctx, &authv1.GetJWKSRequest{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	keys := make(map[string]publicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		k, err := parseJWK(jwk)
+		if err != nil {
+			v.log.Warn("skipping unsupported jwk", slog.String("kid", jwk.Kid), slog.String("error", err.Error()))
+			continue
+		}
+		keys[jwk.Kid] = k
+	}
+
+	v.mu.Lock()
+	v.jwks = jwks
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+
+	return nil
+}
+
+func parseJWK(jwk *authv1.JWK) (publicKey, error) {
+	switch jwk.Kty {
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return publicKey{}, ErrUnknownKey
+		}
+		return publicKey{alg: jwk.Alg, key: ed25519.PublicKey(x)}, nil
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return publicKey{}, ErrUnknownKey
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return publicKey{}, ErrUnknownKey
+		}
+		return publicKey{alg: jwk.Alg, key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	default:
+		return publicKey{}, ErrUnknownKey
+	}
+}
+
+func verify(k publicKey, data, sig []byte) bool {
+	switch pub := k.key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, data, sig)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return false
+	}
+}
+
+func decodeJSON(s string, dest any) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dest)
+}
@@ -1,22 +1,32 @@
 package s3
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
-	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/google/uuid"
 )
 
+var _ storage.Storage = (*S3Storage)(nil)
+
 type S3Storage struct {
 	*s3.S3
 	bucket *string
 	log    *slog.Logger
+
+	uploadURLTTL   time.Duration
+	downloadURLTTL time.Duration
 }
 
 func NewS3Storage(l *slog.Logger, cfg config.S3) *S3Storage {
@@ -42,50 +52,174 @@ func NewS3Storage(l *slog.Logger, cfg config.S3) *S3Storage {
 	s3Client := s3.New(newSession)
 	log.Error("successfully connected to s3")
 
-	return &S3Storage{s3Client, &cfg.BUCKET_NAME, l}
+	return &S3Storage{
+		S3:             s3Client,
+		bucket:         &cfg.BUCKET_NAME,
+		log:            l,
+		uploadURLTTL:   cfg.UPLOAD_URL_TTL,
+		downloadURLTTL: cfg.DOWNLOAD_URL_TTL,
+	}
+}
+
+// Name returns the bucket objects are stored in
+func (s *S3Storage) Name() string {
+	return *s.bucket
+}
+
+// URL returns the plain address of an object, it is only reachable for public objects
+func (s *S3Storage) URL(key string) string {
+	return fmt.Sprintf("%s/%s/%s", s.Endpoint, *s.bucket, key)
+}
+
+// Put uploads the content under the key, readers that can't seek are streamed
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType, checksum string) error {
+	const op = "S3Storage.Put"
+
+	body, ok := r.(io.ReadSeeker)
+	if !ok {
+		body = aws.ReadSeekCloser(r)
+	}
+
+	in := &s3.PutObjectInput{
+		Body:          body,
+		Bucket:        s.bucket,
+		Key:           aws.String(key),
+		ContentLength: aws.Int64(size),
+	}
+	if contentType != "" {
+		in.ContentType = aws.String(contentType)
+	}
+	if checksum != "" {
+		in.ChecksumSHA256 = aws.String(checksum)
+	}
+
+	_, err := s.PutObjectWithContext(ctx, in)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, badDigest(err))
+	}
+
+	return nil
 }
 
-func (s *S3Storage) saveToS3(flsCh chan<- entities.FileResp, errCh chan<- error, file entities.File) {
-	uid := uuid.New().String()
-	_, err := s.PutObject(&s3.PutObjectInput{
-		Body:   file.File,
+// Get returns the content of the object, the caller must close it
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *storage.Object, error) {
+	const op = "S3Storage.Get"
+
+	out, err := s.GetObjectWithContext(ctx, &s3.GetObjectInput{
 		Bucket: s.bucket,
-		Key:    aws.String(uid),
+		Key:    aws.String(key),
 	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, notFound(err))
+	}
+
+	return out.Body, &storage.Object{
+		Key:         key,
+		Size:        aws.Int64Value(out.ContentLength),
+		ContentType: aws.StringValue(out.ContentType),
+	}, nil
+}
 
+// PresignPut returns a URL to upload the object with a single PUT and its expiry.
+// The content type, length and the base64 SHA-256 checksum when given are signed,
+// the upload must send exactly these headers.
+func (s *S3Storage) PresignPut(ctx context.Context, key, contentType string, size int64, checksum string) (string, time.Time, error) {
+	const op = "S3Storage.PresignPut"
+
+	in := &s3.PutObjectInput{
+		Bucket:        s.bucket,
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}
+	if checksum != "" {
+		in.ChecksumSHA256 = aws.String(checksum)
+	}
+
+	req, _ := s.PutObjectRequest(in)
+
+	expiresAt := time.Now().Add(s.uploadURLTTL)
+	url, err := req.Presign(s.uploadURLTTL)
 	if err != nil {
-		errCh <- err
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	resp := entities.FileResp{
-		Filename: file.Filename,
-		URL:      fmt.Sprintf("%s/%s/%s", s.Endpoint, *s.bucket, uid),
+	return url, expiresAt, nil
+}
+
+// PresignGet returns a time limited URL to download the object and its expiry
+func (s *S3Storage) PresignGet(ctx context.Context, key string) (string, time.Time, error) {
+	const op = "S3Storage.PresignGet"
+
+	req, _ := s.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: s.bucket,
+		Key:    aws.String(key),
+	})
+
+	expiresAt := time.Now().Add(s.downloadURLTTL)
+	url, err := req.Presign(s.downloadURLTTL)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	flsCh <- resp
+	return url, expiresAt, nil
 }
 
-func (s *S3Storage) Save(files []entities.File) ([]entities.FileResp, error) {
-	resp := make([]entities.FileResp, 0, len(files))
+// Stat returns the size and content type of a stored object
+func (s *S3Storage) Stat(ctx context.Context, key string) (*storage.Object, error) {
+	const op = "S3Storage.Stat"
 
-	flsChan := make(chan entities.FileResp)
-	errChan := make(chan error)
+	out, err := s.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: s.bucket,
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, notFound(err))
+	}
 
-	defer close(flsChan)
-	defer close(errChan)
+	return &storage.Object{
+		Key:         key,
+		Size:        aws.Int64Value(out.ContentLength),
+		ContentType: aws.StringValue(out.ContentType),
+	}, nil
+}
+
+// Delete removes the object, deleting a missing object is not an error
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	const op = "S3Storage.Delete"
+
+	_, err := s.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: s.bucket,
+		Key:    aws.String(key),
+	})
+	if err != nil && !errors.Is(notFound(err), storage.ErrNotFound) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// badDigest maps the checksum errors of S3 to storage.ErrContentMismatch
+func badDigest(err error) error {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == "BadDigest" {
+		return storage.ErrContentMismatch
+	}
+
+	return err
+}
 
-	for _, file := range files {
-		go s.saveToS3(flsChan, errChan, file)
+// notFound maps the missing object errors of S3 to storage.ErrNotFound
+func notFound(err error) error {
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+		return storage.ErrNotFound
 	}
 
-	for i := 0; i < len(files); i++ {
-		select {
-		case url := <-flsChan:
-			resp = append(resp, url)
-		case err := <-errChan:
-			return nil, err
-		}
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return storage.ErrNotFound
 	}
 
-	return resp, nil
+	return err
 }
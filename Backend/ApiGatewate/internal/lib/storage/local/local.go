@@ -0,0 +1,236 @@
+package local
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage"
+)
+
+// _metaSuffix names the sidecar file holding the content type of an object
+const _metaSuffix = ".meta.json"
+
+type meta struct {
+	ContentType string `json:"content_type"`
+}
+
+// Storage keeps objects as files under a directory, they are served by the gateway through signed URLs
+type Storage struct {
+	dir         string
+	signer      *storage.URLSigner
+	uploadTTL   time.Duration
+	downloadTTL time.Duration
+}
+
+var _ storage.SignedStorage = (*Storage)(nil)
+
+func New(l *slog.Logger, cfg config.LocalStorageConfig, signer *storage.URLSigner, urls config.SignedURLConfig) (*Storage, error) {
+	const op = "local.New"
+
+	dir, err := filepath.Abs(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	l.Info("storing media in local directory", slog.String("op", op), slog.String("dir", dir))
+
+	return &Storage{
+		dir:         dir,
+		signer:      signer,
+		uploadTTL:   urls.UploadTTL,
+		downloadTTL: urls.DownloadTTL,
+	}, nil
+}
+
+func (s *Storage) Name() string {
+	return "local"
+}
+
+func (s *Storage) Signer() *storage.URLSigner {
+	return s.signer
+}
+
+// Put writes the object to a temporary file and moves it in place once it matches the size and checksum
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType, checksum string) error {
+	const op = "local.Storage.Put"
+
+	path, err := s.path(key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	sum := sha256.New()
+	n, err := io.Copy(tmp, io.TeeReader(r, sum))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if n != size || (checksum != "" && base64.StdEncoding.EncodeToString(sum.Sum(nil)) != checksum) {
+		return fmt.Errorf("%s: %w", op, storage.ErrContentMismatch)
+	}
+
+	m, err := json.Marshal(meta{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	metaTmp, err := os.CreateTemp(filepath.Dir(path), ".meta-*")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(metaTmp.Name())
+
+	_, err = metaTmp.Write(m)
+	if closeErr := metaTmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = os.Rename(metaTmp.Name(), path+_metaSuffix)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (io.ReadCloser, *storage.Object, error) {
+	const op = "local.Storage.Get"
+
+	obj, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path, _ := s.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, notFound(err))
+	}
+
+	return f, obj, nil
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	const op = "local.Storage.Delete"
+
+	path, err := s.path(key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, p := range []string{path, path + _metaSuffix} {
+		err = os.Remove(p)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) Stat(ctx context.Context, key string) (*storage.Object, error) {
+	const op = "local.Storage.Stat"
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, notFound(err))
+	}
+
+	obj := &storage.Object{
+		Key:  key,
+		Size: info.Size(),
+	}
+
+	raw, err := os.ReadFile(path + _metaSuffix)
+	if err == nil {
+		var m meta
+		if json.Unmarshal(raw, &m) == nil {
+			obj.ContentType = m.ContentType
+		}
+	}
+	if obj.ContentType == "" {
+		obj.ContentType = "application/octet-stream"
+	}
+
+	return obj, nil
+}
+
+func (s *Storage) URL(key string) string {
+	url, _ := s.signer.Sign(storage.SignedRequest{Method: http.MethodGet, Key: key}, 0)
+	return url
+}
+
+func (s *Storage) PresignGet(ctx context.Context, key string) (string, time.Time, error) {
+	url, expiresAt := s.signer.Sign(storage.SignedRequest{Method: http.MethodGet, Key: key}, s.downloadTTL)
+	return url, expiresAt, nil
+}
+
+func (s *Storage) PresignPut(ctx context.Context, key, contentType string, size int64, checksum string) (string, time.Time, error) {
+	url, expiresAt := s.signer.Sign(storage.SignedRequest{
+		Method:      http.MethodPut,
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    checksum,
+	}, s.uploadTTL)
+	return url, expiresAt, nil
+}
+
+// path maps the key to a file inside the storage directory
+func (s *Storage) path(key string) (string, error) {
+	if !storage.ValidKey(key) {
+		return "", storage.ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return storage.ErrNotFound
+	}
+	return err
+}
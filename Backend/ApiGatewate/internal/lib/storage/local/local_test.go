@@ -0,0 +1,162 @@
+package local
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage"
+)
+
+const _testKey = "users/42/object"
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	s, err := New(slog.New(slog.DiscardHandler), config.LocalStorageConfig{Dir: t.TempDir()},
+		storage.NewURLSigner("test-secret", "http://localhost/files"), config.SignedURLConfig{UploadTTL: time.Minute, DownloadTTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// expectObject fails unless the key holds the content with the content type
+func expectObject(t *testing.T, s *Storage, key, content, contentType string) {
+	t.Helper()
+
+	body, obj, err := s.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer body.Close()
+
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content || obj.ContentType != contentType || obj.Size != int64(len(content)) {
+		t.Fatalf("object = %q %s %d bytes, want %q %s", got, obj.ContentType, obj.Size, content, contentType)
+	}
+}
+
+func TestPut(t *testing.T) {
+	const stored = "stored content"
+
+	tests := []struct {
+		name     string
+		content  string
+		size     int64
+		checksum string
+		want     error
+	}{
+		{"matching checksum", "new content", 11, checksum("new content"), nil},
+		{"without checksum", "new content", 11, "", nil},
+		{"empty", "", 0, checksum(""), nil},
+		{"other checksum", "new content", 11, checksum("evil content"), storage.ErrContentMismatch},
+		{"malformed checksum", "new content", 11, "not base64", storage.ErrContentMismatch},
+		{"shorter than the size", "new content", 12, "", storage.ErrContentMismatch},
+		{"longer than the size", "new content", 10, "", storage.ErrContentMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStorage(t)
+			ctx := context.Background()
+
+			err := s.Put(ctx, _testKey, strings.NewReader(stored), int64(len(stored)), "text/plain", checksum(stored))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = s.Put(ctx, _testKey, strings.NewReader(tt.content), tt.size, "image/png", tt.checksum)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Put = %v, want %v", err, tt.want)
+			}
+
+			// A rejected upload leaves the stored object and its content type as they were
+			if tt.want != nil {
+				expectObject(t, s, _testKey, stored, "text/plain")
+			} else {
+				expectObject(t, s, _testKey, tt.content, "image/png")
+			}
+
+			// Nothing but the object and its metadata is left behind
+			entries, err := os.ReadDir(filepath.Join(s.dir, filepath.Dir(_testKey)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 2 {
+				var names []string
+				for _, e := range entries {
+					names = append(names, e.Name())
+				}
+				t.Fatalf("directory holds %v", names)
+			}
+		})
+	}
+}
+
+func TestPutRejectsMismatchWithoutStoringIt(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	err := s.Put(ctx, _testKey, strings.NewReader("content"), 7, "text/plain", checksum("other"))
+	if !errors.Is(err, storage.ErrContentMismatch) {
+		t.Fatalf("Put = %v, want %v", err, storage.ErrContentMismatch)
+	}
+
+	_, err = s.Stat(ctx, _testKey)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Stat = %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"", "../escape", "users/../../escape", "/abs", "users/42/"} {
+		err := s.Put(ctx, key, strings.NewReader("x"), 1, "text/plain", "")
+		if !errors.Is(err, storage.ErrInvalidKey) {
+			t.Fatalf("Put(%q) = %v, want %v", key, err, storage.ErrInvalidKey)
+		}
+		if _, _, err := s.Get(ctx, key); !errors.Is(err, storage.ErrInvalidKey) {
+			t.Fatalf("Get(%q) = %v, want %v", key, err, storage.ErrInvalidKey)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	err := s.Put(ctx, _testKey, strings.NewReader("content"), 7, "text/plain", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := s.Delete(ctx, _testKey); err != nil {
+			t.Fatalf("Delete = %v", err)
+		}
+	}
+
+	if _, _, err := s.Get(ctx, _testKey); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Get = %v, want %v", err, storage.ErrNotFound)
+	}
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage"
+)
+
+type object struct {
+	data        []byte
+	contentType string
+}
+
+// Storage keeps objects in memory, it is meant for tests and throwaway environments
+type Storage struct {
+	signer      *storage.URLSigner
+	uploadTTL   time.Duration
+	downloadTTL time.Duration
+
+	mu      sync.RWMutex
+	objects map[string]object
+}
+
+var _ storage.SignedStorage = (*Storage)(nil)
+
+func New(signer *storage.URLSigner, urls config.SignedURLConfig) *Storage {
+	return &Storage{
+		signer:      signer,
+		uploadTTL:   urls.UploadTTL,
+		downloadTTL: urls.DownloadTTL,
+		objects:     make(map[string]object),
+	}
+}
+
+func (s *Storage) Name() string {
+	return "memory"
+}
+
+func (s *Storage) Signer() *storage.URLSigner {
+	return s.signer
+}
+
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType, checksum string) error {
+	const op = "memory.Storage.Put"
+
+	if !storage.ValidKey(key) {
+		return fmt.Errorf("%s: %w", op, storage.ErrInvalidKey)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if int64(len(data)) != size || (checksum != "" && base64.StdEncoding.EncodeToString(sum[:]) != checksum) {
+		return fmt.Errorf("%s: %w", op, storage.ErrContentMismatch)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = object{data: data, contentType: contentType}
+	return nil
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (io.ReadCloser, *storage.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.objects[key]
+	if !ok {
+		return nil, nil, storage.ErrNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(o.data)), info(key, o), nil
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *Storage) Stat(ctx context.Context, key string) (*storage.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.objects[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return info(key, o), nil
+}
+
+func (s *Storage) URL(key string) string {
+	url, _ := s.signer.Sign(storage.SignedRequest{Method: http.MethodGet, Key: key}, 0)
+	return url
+}
+
+func (s *Storage) PresignGet(ctx context.Context, key string) (string, time.Time, error) {
+	url, expiresAt := s.signer.Sign(storage.SignedRequest{Method: http.MethodGet, Key: key}, s.downloadTTL)
+	return url, expiresAt, nil
+}
+
+func (s *Storage) PresignPut(ctx context.Context, key, contentType string, size int64, checksum string) (string, time.Time, error) {
+	url, expiresAt := s.signer.Sign(storage.SignedRequest{
+		Method:      http.MethodPut,
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    checksum,
+	}, s.uploadTTL)
+	return url, expiresAt, nil
+}
+
+func info(key string, o object) *storage.Object {
+	return &storage.Object{
+		Key:         key,
+		Size:        int64(len(o.data)),
+		ContentType: o.contentType,
+	}
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBadSignature = errors.New("bad signature")
+	ErrURLExpired   = errors.New("url expired")
+)
+
+// Query parameters of signed URLs
+const (
+	QueryExpires   = "expires"
+	QuerySignature = "signature"
+)
+
+// SignedRequest is what a signed URL allows. Uploads also pin the content type, size and checksum,
+// they must be sent as the Content-Type, Content-Length and X-Amz-Checksum-Sha256 headers.
+type SignedRequest struct {
+	Method      string
+	Key         string
+	ContentType string
+	Size        int64
+	Checksum    string
+}
+
+func (r SignedRequest) payload(expires int64) string {
+	return strings.Join([]string{
+		r.Method, r.Key, r.ContentType, strconv.FormatInt(r.Size, 10), r.Checksum, strconv.FormatInt(expires, 10),
+	}, "\n")
+}
+
+// URLSigner issues and verifies URLs of the gateway file route
+type URLSigner struct {
+	secret  []byte
+	baseURL string
+}
+
+// NewURLSigner returns a signer of URLs under baseURL, e.g. "http://localhost:8080/api/v1/files"
+func NewURLSigner(secret, baseURL string) *URLSigner {
+	return &URLSigner{
+		secret:  []byte(secret),
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Sign returns the URL allowing the request and its expiry, a zero ttl never expires
+func (s *URLSigner) Sign(req SignedRequest, ttl time.Duration) (string, time.Time) {
+	var expiresAt time.Time
+	var expires int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+		expires = expiresAt.Unix()
+	}
+
+	q := url.Values{}
+	q.Set(QueryExpires, strconv.FormatInt(expires, 10))
+	q.Set(QuerySignature, s.signature(req, expires))
+
+	return s.baseURL + "/" + escapeKey(req.Key) + "?" + q.Encode(), expiresAt
+}
+
+// Verify checks the expiry and signature taken from a URL against the request
+func (s *URLSigner) Verify(req SignedRequest, expires, signature string) error {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrBadSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.signature(req, exp))) {
+		return ErrBadSignature
+	}
+
+	if exp != 0 && time.Now().Unix() > exp {
+		return ErrURLExpired
+	}
+
+	return nil
+}
+
+func (s *URLSigner) signature(req SignedRequest, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(req.payload(expires)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// ValidKey reports whether the key is a relative slash separated path without dot segments
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return false
+	}
+
+	for _, p := range strings.Split(key, "/") {
+		if p == "" || p == "." || p == ".." || strings.ContainsRune(p, '\\') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const _testBaseURL = "http://localhost:8080/api/v1/files"
+
+// parse returns the key, expiry and signature of a signed URL
+func parse(t *testing.T, signed string) (string, string, string) {
+	t.Helper()
+
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, ok := strings.CutPrefix(u.Path, "/api/v1/files/")
+	if !ok {
+		t.Fatalf("%s is not under the base url", signed)
+	}
+
+	q := u.Query()
+	return key, q.Get(QueryExpires), q.Get(QuerySignature)
+}
+
+func TestURLSignerVerify(t *testing.T) {
+	s := NewURLSigner("test-secret", _testBaseURL+"/")
+	upload := SignedRequest{
+		Method:      "PUT",
+		Key:         "pending/42/file name.png",
+		ContentType: "image/png",
+		Size:        1024,
+		Checksum:    "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+	}
+
+	signed, expiresAt := s.Sign(upload, time.Minute)
+	key, expires, signature := parse(t, signed)
+	if key != upload.Key {
+		t.Fatalf("key = %q, want %q", key, upload.Key)
+	}
+	if expires != strconv.FormatInt(expiresAt.Unix(), 10) {
+		t.Fatalf("expires = %s, want %d", expires, expiresAt.Unix())
+	}
+
+	changed := func(f func(r *SignedRequest)) SignedRequest {
+		r := upload
+		f(&r)
+		return r
+	}
+	later := strconv.FormatInt(expiresAt.Add(time.Hour).Unix(), 10)
+	past := time.Now().Add(-time.Minute).Unix()
+
+	tests := []struct {
+		name      string
+		signer    *URLSigner
+		req       SignedRequest
+		expires   string
+		signature string
+		want      error
+	}{
+		{"valid", s, upload, expires, signature, nil},
+		{"other method", s, changed(func(r *SignedRequest) { r.Method = "GET" }), expires, signature, ErrBadSignature},
+		{"other key", s, changed(func(r *SignedRequest) { r.Key = "users/42/file name.png" }), expires, signature, ErrBadSignature},
+		{"other content type", s, changed(func(r *SignedRequest) { r.ContentType = "text/html" }), expires, signature, ErrBadSignature},
+		{"other size", s, changed(func(r *SignedRequest) { r.Size = 1025 }), expires, signature, ErrBadSignature},
+		{"no checksum", s, changed(func(r *SignedRequest) { r.Checksum = "" }), expires, signature, ErrBadSignature},
+		{"extended expiry", s, upload, later, signature, ErrBadSignature},
+		{"no expiry", s, upload, "0", signature, ErrBadSignature},
+		{"malformed expiry", s, upload, "soon", signature, ErrBadSignature},
+		{"empty signature", s, upload, expires, "", ErrBadSignature},
+		{"upper case signature", s, upload, expires, strings.ToUpper(signature), ErrBadSignature},
+		{"other secret", NewURLSigner("other-secret", _testBaseURL), upload, expires, signature, ErrBadSignature},
+		{"expired", s, upload, strconv.FormatInt(past, 10), s.signature(upload, past), ErrURLExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.signer.Verify(tt.req, tt.expires, tt.signature)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLSignerWithoutExpiry(t *testing.T) {
+	s := NewURLSigner("test-secret", _testBaseURL)
+	req := SignedRequest{Method: "GET", Key: "users/42/avatar"}
+
+	signed, expiresAt := s.Sign(req, 0)
+	if !expiresAt.IsZero() {
+		t.Fatalf("expiresAt = %v, want zero", expiresAt)
+	}
+
+	_, expires, signature := parse(t, signed)
+	if expires != "0" {
+		t.Fatalf("expires = %s, want 0", expires)
+	}
+	if err := s.Verify(req, expires, signature); err != nil {
+		t.Fatalf("Verify = %v", err)
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"users/42/file", true},
+		{"pending/42/0b6c1a4e-5d1b-4c1e-9d55-0f6b6f4f1a7e", true},
+		{"file name.png", true},
+		{"", false},
+		{"/users/42/file", false},
+		{"users/42/", false},
+		{"users//file", false},
+		{"users/./file", false},
+		{"users/../file", false},
+		{"..", false},
+		{"users\\..\\file", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidKey(tt.key); got != tt.want {
+			t.Errorf("ValidKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
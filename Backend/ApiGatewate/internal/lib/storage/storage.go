@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrNotFound        = errors.New("object not found")
+	ErrInvalidKey      = errors.New("invalid object key")
+	ErrContentMismatch = errors.New("content does not match its size or checksum")
+)
+
+// Object describes a stored object
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Storage keeps media objects under slash separated keys
+type Storage interface {
+	// Name identifies where objects are kept, e.g. the bucket
+	Name() string
+	// Put stores the object. The content must be size bytes long and match the base64 SHA-256
+	// checksum when given, otherwise ErrContentMismatch is returned and a stored object is left as is.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType, checksum string) error
+	// Get returns the content of the object, the caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	// Delete removes the object, deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*Object, error)
+	// URL returns the permanent address of a public object
+	URL(key string) string
+	// PresignGet returns a time limited URL to download the object and its expiry
+	PresignGet(ctx context.Context, key string) (string, time.Time, error)
+	// PresignPut returns a URL to upload the object with a single PUT and its expiry.
+	// The content type, size and the base64 SHA-256 checksum when given are part of the signature.
+	PresignPut(ctx context.Context, key, contentType string, size int64, checksum string) (string, time.Time, error)
+}
+
+// SignedStorage is a storage whose presigned URLs point to the gateway itself
+type SignedStorage interface {
+	Storage
+	Signer() *URLSigner
+}
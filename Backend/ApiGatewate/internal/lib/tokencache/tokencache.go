@@ -0,0 +1,219 @@
+package tokencache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/identity"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// _maxTTL bounds how long a revoked token may still be accepted by the gateway
+const _maxTTL = time.Minute
+
+// Loader asks the auth service for the owner of a token
+type Loader func(ctx context.Context) (identity.Identity, error)
+
+type entry struct {
+	key       string
+	id        identity.Identity
+	err       error
+	expiresAt time.Time
+}
+
+// Cache is a size bounded LRU of access token lookups keyed by the token digest.
+// Invalid tokens are cached as well, for a separate shorter ttl.
+// A nil Cache performs every lookup.
+type Cache struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+	size        int
+
+	mu      sync.Mutex
+	items   map[string]*list.Element
+	order   *list.List
+	version uint64
+	group   singleflight.Group
+}
+
+// New returns a cache for the config, or nil when caching is disabled
+func New(log *slog.Logger, cfg config.TokenCacheConfig) *Cache {
+	if !cfg.Enabled || cfg.Size <= 0 || cfg.TTL <= 0 {
+		log.Info("token cache disabled")
+		return nil
+	}
+
+	ttl := cfg.TTL
+	if ttl > _maxTTL {
+		log.Warn("token cache ttl is too long, capping it", slog.Duration("ttl", ttl), slog.Duration("max", _maxTTL))
+		ttl = _maxTTL
+	}
+
+	return &Cache{
+		ttl:         ttl,
+		negativeTTL: min(cfg.NegativeTTL, ttl),
+		size:        cfg.Size,
+		items:       make(map[string]*list.Element),
+		order:       list.New(),
+	}
+}
+
+// Get returns the owner of the token, concurrent misses for the same token share one load
+func (c *Cache) Get(ctx context.Context, token string, load Loader) (identity.Identity, error) {
+	if c == nil {
+		return load(ctx)
+	}
+
+	key := digest(token)
+
+	e, ok := c.lookup(key)
+	if ok {
+		return e.id, e.err
+	}
+
+	c.mu.Lock()
+	version := c.version
+	c.mu.Unlock()
+
+	res, err, _ := c.group.Do(key, func() (any, error) {
+		// The load is shared, a caller going away must not fail the others waiting for it
+		loadCtx := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			loadCtx, cancel = context.WithDeadline(loadCtx, deadline)
+			defer cancel()
+		}
+
+		id, err := load(loadCtx)
+		c.store(key, version, id, err)
+		return id, err
+	})
+	if err != nil {
+		return identity.Identity{}, err
+	}
+
+	return res.(identity.Identity), nil
+}
+
+// Invalidate drops the entry of the token
+func (c *Cache) Invalidate(token string) {
+	if c == nil {
+		return
+	}
+
+	key := digest(token)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	c.group.Forget(key)
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// InvalidateUser drops the entries of every token of the user
+func (c *Cache) InvalidateUser(userID int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		e := el.Value.(*entry)
+		if e.err == nil && e.id.UserID == userID {
+			c.group.Forget(e.key)
+			c.remove(el)
+		}
+		el = next
+	}
+}
+
+func (c *Cache) lookup(key string) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if !time.Now().Before(e.expiresAt) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return e, true
+}
+
+// store keeps the result of a load, unless an invalidation happened while it was in flight
+func (c *Cache) store(key string, version uint64, id identity.Identity, err error) {
+	ttl := c.ttl
+	if err != nil {
+		if !invalidToken(err) || c.negativeTTL <= 0 {
+			return
+		}
+		ttl = c.negativeTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version != version {
+		return
+	}
+
+	e := &entry{
+		key:       key,
+		id:        id,
+		err:       err,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	if el, ok := c.items[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(e)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry).key)
+}
+
+// invalidToken reports whether the auth service rejected the token itself,
+// transport and internal errors must not be cached
+func invalidToken(err error) bool {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.NotFound, codes.PermissionDenied, codes.FailedPrecondition:
+		return true
+	default:
+		return false
+	}
+}
+
+func digest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,363 @@
+package tokencache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/identity"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestCache(t *testing.T, size int) *Cache {
+	t.Helper()
+
+	c := New(slog.New(slog.DiscardHandler), config.TokenCacheConfig{
+		Enabled:     true,
+		Size:        size,
+		TTL:         time.Minute,
+		NegativeTTL: time.Second,
+	})
+	if c == nil {
+		t.Fatal("cache is disabled")
+	}
+	return c
+}
+
+// loader counts its calls and returns the identity of the user or the error
+type loader struct {
+	calls atomic.Int64
+	id    identity.Identity
+	err   error
+}
+
+func (l *loader) load(ctx context.Context) (identity.Identity, error) {
+	l.calls.Add(1)
+	return l.id, l.err
+}
+
+func (l *loader) expectCalls(t *testing.T, want int64) {
+	t.Helper()
+	if got := l.calls.Load(); got != want {
+		t.Fatalf("loader called %d times, want %d", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.DiscardHandler)
+
+	disabled := []config.TokenCacheConfig{
+		{Enabled: false, Size: 10, TTL: time.Second},
+		{Enabled: true, Size: 0, TTL: time.Second},
+		{Enabled: true, Size: 10, TTL: 0},
+	}
+	for _, cfg := range disabled {
+		if c := New(log, cfg); c != nil {
+			t.Fatalf("New(%+v) is enabled", cfg)
+		}
+	}
+
+	c := New(log, config.TokenCacheConfig{Enabled: true, Size: 10, TTL: time.Hour, NegativeTTL: 2 * time.Hour})
+	if c.ttl != _maxTTL || c.negativeTTL != _maxTTL {
+		t.Fatalf("ttl %v, negative ttl %v; want both capped to %v", c.ttl, c.negativeTTL, _maxTTL)
+	}
+}
+
+func TestGetCachesIdentity(t *testing.T) {
+	c := newTestCache(t, 10)
+	l := &loader{id: identity.Identity{UserID: 1, Role: "user"}}
+
+	for range 3 {
+		id, err := c.Get(context.Background(), "token", l.load)
+		if err != nil || id != l.id {
+			t.Fatalf("Get = %+v, %v", id, err)
+		}
+	}
+	l.expectCalls(t, 1)
+
+	// Another token is another entry
+	_, _ = c.Get(context.Background(), "other", l.load)
+	l.expectCalls(t, 2)
+}
+
+func TestGetReloadsExpired(t *testing.T) {
+	c := newTestCache(t, 10)
+	l := &loader{id: identity.Identity{UserID: 1}}
+
+	_, _ = c.Get(context.Background(), "token", l.load)
+	c.items[digest("token")].Value.(*entry).expiresAt = time.Now().Add(-time.Millisecond)
+
+	_, _ = c.Get(context.Background(), "token", l.load)
+	l.expectCalls(t, 2)
+}
+
+func TestGetEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newTestCache(t, 2)
+	l := &loader{id: identity.Identity{UserID: 1}}
+	get := func(token string) {
+		t.Helper()
+		if _, err := c.Get(context.Background(), token, l.load); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get("a")
+	get("b")
+	get("a") // b is now the least recently used
+	get("c")
+	l.expectCalls(t, 3)
+
+	if c.order.Len() != 2 || len(c.items) != 2 {
+		t.Fatalf("%d entries in order, %d in items; want 2", c.order.Len(), len(c.items))
+	}
+
+	get("a")
+	get("c")
+	l.expectCalls(t, 3)
+
+	get("b")
+	l.expectCalls(t, 4)
+}
+
+func TestGetNegativeCaching(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		negativeTTL time.Duration
+		calls       int64
+	}{
+		{"invalid token", status.Error(codes.Unauthenticated, "invalid token"), time.Second, 1},
+		{"unknown token", status.Error(codes.NotFound, "token not found"), time.Second, 1},
+		{"blocked user", status.Error(codes.PermissionDenied, "blocked"), time.Second, 1},
+		{"no negative ttl", status.Error(codes.Unauthenticated, "invalid token"), 0, 3},
+		{"auth service down", status.Error(codes.Unavailable, "unavailable"), time.Second, 3},
+		{"internal error", status.Error(codes.Internal, "internal"), time.Second, 3},
+		{"transport error", errors.New("connection reset"), time.Second, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCache(t, 10)
+			c.negativeTTL = tt.negativeTTL
+			l := &loader{err: tt.err}
+
+			for range 3 {
+				_, err := c.Get(context.Background(), "token", l.load)
+				if status.Code(err) != status.Code(tt.err) {
+					t.Fatalf("Get error = %v, want %v", err, tt.err)
+				}
+			}
+			l.expectCalls(t, tt.calls)
+		})
+	}
+}
+
+func TestGetNegativeEntryExpiresFirst(t *testing.T) {
+	c := newTestCache(t, 10)
+	l := &loader{err: status.Error(codes.Unauthenticated, "invalid token")}
+
+	_, _ = c.Get(context.Background(), "token", l.load)
+
+	e := c.items[digest("token")].Value.(*entry)
+	if ttl := time.Until(e.expiresAt); ttl > c.negativeTTL {
+		t.Fatalf("negative entry expires in %v, want at most %v", ttl, c.negativeTTL)
+	}
+}
+
+func TestGetSharesConcurrentLoads(t *testing.T) {
+	c := newTestCache(t, 10)
+
+	release := make(chan struct{})
+	var calls atomic.Int64
+	load := func(ctx context.Context) (identity.Identity, error) {
+		calls.Add(1)
+		<-release
+		return identity.Identity{UserID: 1}, nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := c.Get(context.Background(), "token", load)
+			if err == nil && id.UserID != 1 {
+				err = errors.New("wrong identity")
+			}
+			errs <- err
+		}()
+	}
+
+	// Let every caller join the load before it finishes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("loader called %d times, want 1", got)
+	}
+}
+
+func TestGetSharedLoadOutlivesCanceledCaller(t *testing.T) {
+	c := newTestCache(t, 10)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context) (identity.Identity, error) {
+		close(started)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return identity.Identity{}, err
+		}
+		return identity.Identity{UserID: 1}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Get(ctx, "token", load)
+		done <- err
+	}()
+
+	<-started
+	cancel()
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("canceled caller failed the shared load: %v", err)
+	}
+
+	id, err := c.Get(context.Background(), "token", func(ctx context.Context) (identity.Identity, error) {
+		return identity.Identity{}, errors.New("not cached")
+	})
+	if err != nil || id.UserID != 1 {
+		t.Fatalf("Get = %+v, %v", id, err)
+	}
+}
+
+func TestGetKeepsCallerDeadline(t *testing.T) {
+	c := newTestCache(t, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	want, _ := ctx.Deadline()
+
+	_, _ = c.Get(ctx, "token", func(ctx context.Context) (identity.Identity, error) {
+		got, ok := ctx.Deadline()
+		if !ok || !got.Equal(want) {
+			t.Errorf("load deadline = %v, %v; want %v", got, ok, want)
+		}
+		return identity.Identity{UserID: 1}, nil
+	})
+}
+
+func TestInvalidate(t *testing.T) {
+	c := newTestCache(t, 10)
+	l := &loader{id: identity.Identity{UserID: 1}}
+
+	_, _ = c.Get(context.Background(), "a", l.load)
+	_, _ = c.Get(context.Background(), "b", l.load)
+	c.Invalidate("a")
+
+	_, _ = c.Get(context.Background(), "a", l.load)
+	_, _ = c.Get(context.Background(), "b", l.load)
+	l.expectCalls(t, 3)
+}
+
+func TestInvalidateUser(t *testing.T) {
+	c := newTestCache(t, 10)
+	first := &loader{id: identity.Identity{UserID: 1, Role: "user"}}
+	second := &loader{id: identity.Identity{UserID: 2, Role: "user"}}
+	invalid := &loader{err: status.Error(codes.Unauthenticated, "invalid token")}
+
+	_, _ = c.Get(context.Background(), "first-a", first.load)
+	_, _ = c.Get(context.Background(), "first-b", first.load)
+	_, _ = c.Get(context.Background(), "second", second.load)
+	_, _ = c.Get(context.Background(), "invalid", invalid.load)
+
+	c.InvalidateUser(1)
+
+	// The new role is loaded, not served from the cache
+	first.id.Role = "admin"
+	for _, token := range []string{"first-a", "first-b"} {
+		id, _ := c.Get(context.Background(), token, first.load)
+		if id.Role != "admin" {
+			t.Fatalf("%s role = %s, want admin", token, id.Role)
+		}
+	}
+	first.expectCalls(t, 4)
+
+	_, _ = c.Get(context.Background(), "second", second.load)
+	second.expectCalls(t, 1)
+	_, _ = c.Get(context.Background(), "invalid", invalid.load)
+	invalid.expectCalls(t, 1)
+}
+
+func TestInvalidateDuringLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(c *Cache)
+	}{
+		{"token", func(c *Cache) { c.Invalidate("token") }},
+		{"user", func(c *Cache) { c.InvalidateUser(1) }},
+		{"other user", func(c *Cache) { c.InvalidateUser(2) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCache(t, 10)
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			stale := func(ctx context.Context) (identity.Identity, error) {
+				close(started)
+				<-release
+				return identity.Identity{UserID: 1, Role: "user"}, nil
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				_, _ = c.Get(context.Background(), "token", stale)
+			}()
+
+			<-started
+			tt.invalidate(c)
+			close(release)
+			<-done
+
+			// A result loaded before any invalidation is not stored
+			l := &loader{id: identity.Identity{UserID: 1, Role: "admin"}}
+			id, _ := c.Get(context.Background(), "token", l.load)
+			if id.Role != "admin" {
+				t.Fatalf("role = %s, the load started before the invalidation was cached", id.Role)
+			}
+		})
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+	l := &loader{id: identity.Identity{UserID: 1}}
+
+	for range 2 {
+		_, _ = c.Get(context.Background(), "token", l.load)
+	}
+	c.Invalidate("token")
+	c.InvalidateUser(1)
+	l.expectCalls(t, 2)
+}
@@ -0,0 +1,164 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+	"github.com/gabriel-vasile/mimetype"
+)
+
+var (
+	ErrUnknownCategory     = errors.New("unknown upload category")
+	ErrNoFiles             = errors.New("you must send at least one file")
+	ErrTooManyFiles        = errors.New("too many files")
+	ErrFileTooLarge        = errors.New("file is too large")
+	ErrTotalTooLarge       = errors.New("files are too large in total")
+	ErrTypeNotAllowed      = errors.New("file type is not allowed")
+	ErrExtensionNotAllowed = errors.New("file extension is not allowed")
+)
+
+// Policies holds the upload policy of every media category
+type Policies map[string]*Policy
+
+func NewPolicies(cfg map[string]config.UploadPolicy) Policies {
+	p := make(Policies, len(cfg))
+	for category, c := range cfg {
+		p[category] = newPolicy(category, c)
+	}
+	return p
+}
+
+// Get returns the policy of the category
+func (p Policies) Get(category string) (*Policy, error) {
+	policy, ok := p[category]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCategory, category)
+	}
+	return policy, nil
+}
+
+// Policy limits the files of a media category
+type Policy struct {
+	Category     string
+	Public       bool
+	MaxFileSize  int64
+	MaxFiles     int
+	MaxTotalSize int64
+	// Images sends the images of the category through the image pipeline
+	Images bool
+
+	types      []string
+	extensions map[string]bool
+}
+
+func newPolicy(category string, cfg config.UploadPolicy) *Policy {
+	extensions := make(map[string]bool, len(cfg.Extensions))
+	for _, ext := range cfg.Extensions {
+		extensions[strings.ToLower(ext)] = true
+	}
+
+	return &Policy{
+		Category:     category,
+		Public:       cfg.Public,
+		MaxFileSize:  cfg.MaxFileSize,
+		MaxFiles:     cfg.MaxFiles,
+		MaxTotalSize: cfg.MaxTotalSize,
+		Images:       cfg.Images,
+		types:        cfg.AllowedTypes,
+		extensions:   extensions,
+	}
+}
+
+// CheckBatch validates the number and the declared sizes of the files of one upload
+func (p *Policy) CheckBatch(sizes ...int64) error {
+	if len(sizes) == 0 {
+		return ErrNoFiles
+	}
+	if len(sizes) > p.MaxFiles {
+		return fmt.Errorf("%w: at most %d", ErrTooManyFiles, p.MaxFiles)
+	}
+
+	var total int64
+	for _, size := range sizes {
+		if err := p.CheckSize(size); err != nil {
+			return err
+		}
+		total += size
+	}
+	if total > p.MaxTotalSize {
+		return fmt.Errorf("%w: at most %d bytes", ErrTotalTooLarge, p.MaxTotalSize)
+	}
+
+	return nil
+}
+
+// CheckSize validates the size of a single file
+func (p *Policy) CheckSize(size int64) error {
+	if size <= 0 || size > p.MaxFileSize {
+		return fmt.Errorf("%w: at most %d bytes", ErrFileTooLarge, p.MaxFileSize)
+	}
+	return nil
+}
+
+// CheckName validates the extension of the file name
+func (p *Policy) CheckName(filename string) error {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !p.extensions[ext] {
+		return fmt.Errorf("%w: %q", ErrExtensionNotAllowed, ext)
+	}
+	return nil
+}
+
+// CheckType returns the allowed type matching a content type declared by the client,
+// for uploads whose content can only be inspected once it is stored
+func (p *Policy) CheckType(contentType string) (string, error) {
+	m := mimetype.Lookup(contentType)
+	if m == nil {
+		return "", fmt.Errorf("%w: %q", ErrTypeNotAllowed, contentType)
+	}
+	return p.allowed(m)
+}
+
+// DetectLimit is the number of leading bytes inspected to detect the content type
+const DetectLimit = 3072
+
+// Detect returns the allowed type matching the magic bytes of the content and rewinds it
+func (p *Policy) Detect(r io.ReadSeeker) (string, error) {
+	m, err := mimetype.DetectReader(r)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return p.allowed(m)
+}
+
+// DetectHeader returns the allowed type matching the leading bytes of the content
+func (p *Policy) DetectHeader(head []byte) (string, error) {
+	return p.allowed(mimetype.Detect(head))
+}
+
+func (p *Policy) allowed(m *mimetype.MIME) (string, error) {
+	t := p.match(m)
+	if t == "" {
+		return "", fmt.Errorf("%w: %q", ErrTypeNotAllowed, m.String())
+	}
+	return t, nil
+}
+
+// match returns the allowed type the detected type is or an alias of
+func (p *Policy) match(m *mimetype.MIME) string {
+	for _, t := range p.types {
+		if m.Is(t) {
+			return t
+		}
+	}
+	return ""
+}
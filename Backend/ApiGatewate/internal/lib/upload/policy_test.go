@@ -0,0 +1,163 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+)
+
+func testPolicy() *Policy {
+	return newPolicy("avatars", config.UploadPolicy{
+		AllowedTypes: []string{"image/jpeg", "image/png"},
+		Extensions:   []string{".jpg", ".JPEG", ".png"},
+		MaxFileSize:  100,
+		MaxFiles:     3,
+		MaxTotalSize: 250,
+	})
+}
+
+func encoded(t *testing.T, encode func(io.Writer, image.Image) error) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := encode(&b, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	pngData := encoded(t, png.Encode)
+	jpegData := encoded(t, func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, nil) })
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+		err     error
+	}{
+		{"png", pngData, "image/png", nil},
+		{"jpeg", jpegData, "image/jpeg", nil},
+		{"html", []byte("<!DOCTYPE html><html><script>alert(1)</script></html>"), "", ErrTypeNotAllowed},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), "", ErrTypeNotAllowed},
+		{"pdf", []byte("%PDF-1.7\n"), "", ErrTypeNotAllowed},
+		{"text", []byte("just text"), "", ErrTypeNotAllowed},
+		{"empty", nil, "", ErrTypeNotAllowed},
+		{"png signature cut off", pngData[:4], "", ErrTypeNotAllowed},
+		{"html after a png signature", append(pngData[:8:8], "<html><script>alert(1)</script></html>"...), "image/png", nil},
+		{"png after html", append([]byte("<html>"), pngData...), "", ErrTypeNotAllowed},
+	}
+
+	p := testPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(tt.content)
+			got, err := p.Detect(r)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Fatalf("Detect = %q, %v; want %q, %v", got, err, tt.want, tt.err)
+			}
+
+			if err == nil {
+				rest, _ := io.ReadAll(r)
+				if !bytes.Equal(rest, tt.content) {
+					t.Fatal("content was not rewound")
+				}
+			}
+
+			// The header of a presigned upload is detected the same way
+			head := tt.content[:min(len(tt.content), DetectLimit)]
+			got, err = p.DetectHeader(head)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Fatalf("DetectHeader = %q, %v; want %q, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+		err         error
+	}{
+		{"image/png", "image/png", nil},
+		{"image/jpeg", "image/jpeg", nil},
+		{"text/html", "", ErrTypeNotAllowed},
+		{"image/svg+xml", "", ErrTypeNotAllowed},
+		{"application/x-unknown", "", ErrTypeNotAllowed},
+		{"", "", ErrTypeNotAllowed},
+	}
+
+	p := testPolicy()
+	for _, tt := range tests {
+		got, err := p.CheckType(tt.contentType)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("CheckType(%q) = %q, %v; want %q, %v", tt.contentType, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	tests := []struct {
+		filename string
+		err      error
+	}{
+		{"photo.png", nil},
+		{"photo.PNG", nil},
+		{"photo.jpeg", nil},
+		{"photo.png.html", ErrExtensionNotAllowed},
+		{"photo", ErrExtensionNotAllowed},
+		{".png", nil},
+		{"", ErrExtensionNotAllowed},
+	}
+
+	p := testPolicy()
+	for _, tt := range tests {
+		if err := p.CheckName(tt.filename); !errors.Is(err, tt.err) {
+			t.Errorf("CheckName(%q) = %v, want %v", tt.filename, err, tt.err)
+		}
+	}
+}
+
+func TestCheckBatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		sizes []int64
+		err   error
+	}{
+		{"single file", []int64{100}, nil},
+		{"at the total limit", []int64{100, 100, 50}, nil},
+		{"no files", nil, ErrNoFiles},
+		{"too many files", []int64{1, 1, 1, 1}, ErrTooManyFiles},
+		{"file too large", []int64{101}, ErrFileTooLarge},
+		{"empty file", []int64{0}, ErrFileTooLarge},
+		{"negative size", []int64{10, -10}, ErrFileTooLarge},
+		{"total too large", []int64{100, 100, 51}, ErrTotalTooLarge},
+	}
+
+	p := testPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.CheckBatch(tt.sizes...); !errors.Is(err, tt.err) {
+				t.Fatalf("CheckBatch = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPoliciesGet(t *testing.T) {
+	p := NewPolicies(map[string]config.UploadPolicy{"avatars": {}})
+
+	if policy, err := p.Get("avatars"); err != nil || policy.Category != "avatars" {
+		t.Fatalf("Get = %+v, %v", policy, err)
+	}
+	if _, err := p.Get("other"); !errors.Is(err, ErrUnknownCategory) {
+		t.Fatalf("Get = %v, want %v", err, ErrUnknownCategory)
+	}
+}
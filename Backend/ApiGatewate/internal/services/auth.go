@@ -5,21 +5,25 @@ import (
 	"log/slog"
 
 	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/config"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/identity"
 	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 type AuthService struct {
-	log  *slog.Logger
-	cfg  config.AuthServiceConfig
-	conn *grpc.ClientConn
+	log    *slog.Logger
+	cfg    config.AuthServiceConfig
+	signer *identity.Signer
+	conn   *grpc.ClientConn
 }
 
-func NewAuthService(log *slog.Logger, cfg config.AuthServiceConfig) *AuthService {
+// NewAuthService returns the auth service connector, calls carry the caller identity when signer is set
+func NewAuthService(log *slog.Logger, cfg config.AuthServiceConfig, signer *identity.Signer) *AuthService {
 	return &AuthService{
-		log: log,
-		cfg: cfg,
+		log:    log,
+		cfg:    cfg,
+		signer: signer,
 	}
 }
 
@@ -34,6 +38,9 @@ func (s *AuthService) Connect() authv1.AuthServiceClient {
 	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	}
+	if s.signer != nil {
+		opts = append(opts, grpc.WithUnaryInterceptor(s.signer.UnaryClientInterceptor()))
+	}
 
 	conn, err := grpc.NewClient(s.cfg.Addr, opts...)
 	if err != nil {
@@ -0,0 +1,752 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/entities"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/imaging"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/storage"
+	"github.com/Homyakadze14/PsyhoApp/ApiGatewate/internal/lib/upload"
+	authv1 "github.com/Homyakadze14/PsyhoApp/ApiGatewate/proto/gen/auth"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// _mediaManagePermission lets a user read and delete media of other users
+	_mediaManagePermission = "media:manage"
+	// _userObjectsPrefix holds uploaded objects, objects of a user live under <prefix>/<user_id>/
+	_userObjectsPrefix = "users"
+	// _stagingObjectsPrefix holds presigned uploads until they are completed, under <prefix>/<user_id>/<media_id>.
+	// Clients only ever get a URL to upload there, the object of the media is written by the gateway.
+	_stagingObjectsPrefix = "pending"
+	_defaultMediaLimit    = 50
+	// _uploadConcurrency bounds the files of one upload stored at once
+	_uploadConcurrency = 4
+	// _purgeBatchSize bounds the stale pending uploads removed by one purge
+	_purgeBatchSize = 100
+)
+
+var (
+	ErrMediaNotFound  = errors.New("media not found")
+	ErrMediaForbidden = errors.New("media belongs to another user")
+	ErrUploadMismatch = errors.New("uploaded object does not match the presigned upload")
+	ErrBadChecksum    = errors.New("checksum must be a base64 SHA-256 digest")
+)
+
+type MediaRepo interface {
+	Create(ctx context.Context, m *entities.Media) error
+	GetByID(ctx context.Context, id string) (*entities.Media, error)
+	GetByKey(ctx context.Context, key string) (*entities.Media, error)
+	ListByOwner(ctx context.Context, ownerID int64, limit, offset int) ([]entities.Media, error)
+	ListPendingBefore(ctx context.Context, before time.Time, limit int) ([]entities.Media, error)
+	Update(ctx context.Context, m *entities.Media) error
+	Delete(ctx context.Context, id string) error
+	DeletePending(ctx context.Context, id string) (bool, error)
+}
+
+// MediaService keeps the metadata of stored objects and controls access to them
+type MediaService struct {
+	log      *slog.Logger
+	repo     MediaRepo
+	storage  storage.Storage
+	auth     authv1.AuthServiceClient
+	policies upload.Policies
+	images   *imaging.Processor
+	// pendingTTL is how long a presigned upload may stay pending before it is purged
+	pendingTTL time.Duration
+}
+
+func NewMediaService(log *slog.Logger, repo MediaRepo, st storage.Storage, auth authv1.AuthServiceClient, policies upload.Policies, images *imaging.Processor, pendingTTL time.Duration) *MediaService {
+	return &MediaService{
+		log:        log,
+		repo:       repo,
+		storage:    st,
+		auth:       auth,
+		policies:   policies,
+		images:     images,
+		pendingTTL: pendingTTL,
+	}
+}
+
+// Policy returns the upload policy of the media category
+func (s *MediaService) Policy(category string) (*upload.Policy, error) {
+	return s.policies.Get(category)
+}
+
+// Upload checks the files against the policy of the category and stores them as objects of the user.
+// The batch is stored as a whole, files already stored are removed when another one fails.
+func (s *MediaService) Upload(ctx context.Context, ownerID int64, category string, files []entities.File) ([]entities.FileResp, error) {
+	const op = "MediaService.Upload"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", ownerID),
+		slog.String("category", category),
+	)
+
+	policy, err := s.policies.Get(category)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make([]int64, 0, len(files))
+	for _, file := range files {
+		sizes = append(sizes, file.Size)
+	}
+
+	err = policy.CheckBatch(sizes...)
+	if err != nil {
+		log.Info(err.Error())
+		return nil, err
+	}
+
+	for i := range files {
+		err = policy.CheckName(files[i].Filename)
+		if err != nil {
+			log.Info(err.Error())
+			return nil, err
+		}
+
+		// The client header is ignored, the stored type is the one of the content
+		files[i].ContentType, err = policy.Detect(files[i].File)
+		if err != nil {
+			log.Info(err.Error(), slog.String("filename", files[i].Filename))
+			return nil, err
+		}
+	}
+
+	resp := make([]entities.FileResp, len(files))
+	stored := make([]*entities.Media, len(files))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(_uploadConcurrency)
+	for i, file := range files {
+		g.Go(func() error {
+			m, err := s.upload(gctx, ownerID, policy, file)
+			if err != nil {
+				return err
+			}
+			stored[i] = m
+
+			media, err := s.withURL(gctx, m)
+			if err != nil {
+				return err
+			}
+
+			resp[i] = entities.FileResp{
+				ID:         m.ID,
+				Filename:   m.Filename,
+				URL:        media.URL,
+				Thumbnails: media.Thumbnails,
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		if errors.Is(err, imaging.ErrInvalidImage) || errors.Is(err, imaging.ErrImageTooLarge) {
+			log.Info(err.Error())
+		} else {
+			log.Error(err.Error())
+		}
+		s.rollback(stored)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// rollback removes the files of a failed batch that were already stored.
+// Objects whose metadata can't be removed are kept, a leaked object is better than broken media.
+func (s *MediaService) rollback(batch []*entities.Media) {
+	for _, m := range batch {
+		if m == nil {
+			continue
+		}
+
+		err := s.repo.Delete(context.Background(), m.ID)
+		if err != nil {
+			s.log.Error("failed to roll back upload", slog.String("key", m.Key), slog.String("error", err.Error()))
+			continue
+		}
+
+		s.deleteObjects(m)
+	}
+}
+
+func (s *MediaService) upload(ctx context.Context, ownerID int64, policy *upload.Policy, file entities.File) (*entities.Media, error) {
+	// Images are stored as processed, the upload is not kept
+	var thumbnails []imaging.Variant
+	if policy.Images && imaging.Supports(file.ContentType) {
+		res, err := s.images.Process(ctx, file.File)
+		if err != nil {
+			return nil, err
+		}
+
+		file.File = bytes.NewReader(res.Original.Data)
+		file.ContentType = res.Original.ContentType
+		file.Size = int64(len(res.Original.Data))
+		thumbnails = res.Thumbnails
+	}
+
+	checksum, err := sha256Hex(file.File)
+	if err != nil {
+		return nil, err
+	}
+
+	m := s.newMedia(ownerID, policy, file.Filename, file.ContentType, file.Size, checksum)
+	m.Status = entities.MediaReady
+
+	err = s.storage.Put(ctx, m.Key, file.File, file.Size, file.ContentType, "")
+	if err != nil {
+		return nil, err
+	}
+
+	m.Variants, err = s.putVariants(ctx, m, thumbnails)
+	if err != nil {
+		s.deleteObject(m.Key)
+		return nil, err
+	}
+
+	err = s.repo.Create(ctx, m)
+	if err != nil {
+		s.deleteObjects(m)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// process stores the processed version of an uploaded image under the key of the media together with its thumbnails
+func (s *MediaService) process(ctx context.Context, m *entities.Media, r io.Reader) error {
+	res, err := s.images.Process(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	checksum, err := sha256Hex(bytes.NewReader(res.Original.Data))
+	if err != nil {
+		return err
+	}
+
+	size := int64(len(res.Original.Data))
+	err = s.storage.Put(ctx, m.Key, bytes.NewReader(res.Original.Data), size, res.Original.ContentType, "")
+	if err != nil {
+		return err
+	}
+
+	m.ContentType = res.Original.ContentType
+	m.Size = size
+	m.Checksum = checksum
+
+	m.Variants, err = s.putVariants(ctx, m, res.Thumbnails)
+	return err
+}
+
+// putVariants stores the thumbnails of an image next to it, nothing is left behind on failure
+func (s *MediaService) putVariants(ctx context.Context, m *entities.Media, thumbnails []imaging.Variant) ([]entities.MediaVariant, error) {
+	variants := make([]entities.MediaVariant, 0, len(thumbnails))
+	for _, t := range thumbnails {
+		v := entities.MediaVariant{
+			Name:        t.Name,
+			Key:         fmt.Sprintf("%s_%s", m.Key, t.Name),
+			Width:       t.Width,
+			Height:      t.Height,
+			ContentType: t.ContentType,
+			Size:        int64(len(t.Data)),
+		}
+
+		err := s.storage.Put(ctx, v.Key, bytes.NewReader(t.Data), v.Size, v.ContentType, "")
+		if err != nil {
+			for _, stored := range variants {
+				s.deleteObject(stored.Key)
+			}
+			return nil, err
+		}
+
+		variants = append(variants, v)
+	}
+
+	return variants, nil
+}
+
+// Presign records a pending object of the user and returns the URL to upload it
+func (s *MediaService) Presign(ctx context.Context, ownerID int64, req *entities.PresignUploadRequest) (*entities.PresignUploadResponse, error) {
+	const op = "MediaService.Presign"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", ownerID),
+	)
+
+	policy, err := s.policies.Get(req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, err := checkPresign(policy, req)
+	if err != nil {
+		log.Info(err.Error())
+		return nil, err
+	}
+
+	var checksum string
+	if req.ChecksumSHA256 != "" {
+		sum, err := base64.StdEncoding.DecodeString(req.ChecksumSHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, ErrBadChecksum
+		}
+		checksum = hex.EncodeToString(sum)
+	}
+
+	m := s.newMedia(ownerID, policy, req.Filename, contentType, req.Size, checksum)
+	m.Status = entities.MediaPending
+
+	url, expiresAt, err := s.storage.PresignPut(ctx, stagingKey(m), m.ContentType, m.Size, req.ChecksumSHA256)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.repo.Create(ctx, m)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	headers := map[string]string{
+		"Content-Type":   m.ContentType,
+		"Content-Length": fmt.Sprint(m.Size),
+	}
+	if req.ChecksumSHA256 != "" {
+		headers["X-Amz-Checksum-Sha256"] = req.ChecksumSHA256
+	}
+
+	return &entities.PresignUploadResponse{
+		ID:        m.ID,
+		Key:       m.Key,
+		URL:       url,
+		Method:    http.MethodPut,
+		Headers:   headers,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+// Complete checks a presigned upload of the user against what was presigned, copies it to the key
+// of the media and marks it ready. The presigned URL only reaches the staging key, an upload sent
+// through it after the completion changes nothing the media serves.
+func (s *MediaService) Complete(ctx context.Context, ownerID int64, key string) (*entities.Media, error) {
+	const op = "MediaService.Complete"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", ownerID),
+	)
+
+	m, err := s.repo.GetByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.OwnerID != ownerID {
+		return nil, ErrMediaForbidden
+	}
+
+	if m.Status == entities.MediaReady {
+		return m, nil
+	}
+
+	policy, err := s.policies.Get(m.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.promote(ctx, m, policy)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			log.Info(err.Error())
+			return nil, err
+		case errors.Is(err, upload.ErrTypeNotAllowed) || errors.Is(err, ErrUploadMismatch):
+			log.Warn("uploaded content is not allowed", slog.String("key", m.Key), slog.String("error", err.Error()))
+			s.discard(m)
+			return nil, err
+		case errors.Is(err, imaging.ErrInvalidImage) || errors.Is(err, imaging.ErrImageTooLarge):
+			log.Warn("uploaded image can't be processed", slog.String("key", m.Key), slog.String("error", err.Error()))
+			s.discard(m)
+			return nil, err
+		}
+		log.Error(err.Error())
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m.Status = entities.MediaReady
+	err = s.repo.Update(ctx, m)
+	if err != nil {
+		log.Error(err.Error())
+		s.deleteObjects(m)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.deleteObject(stagingKey(m))
+	return m, nil
+}
+
+// PurgeStalePending removes presigned uploads that stayed pending longer than the pending ttl
+// together with whatever was uploaded through them
+func (s *MediaService) PurgeStalePending(ctx context.Context) (int64, error) {
+	const op = "MediaService.PurgeStalePending"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if s.pendingTTL <= 0 {
+		return 0, nil
+	}
+
+	var purged int64
+	for {
+		stale, err := s.repo.ListPendingBefore(ctx, time.Now().Add(-s.pendingTTL), _purgeBatchSize)
+		if err != nil {
+			log.Error(err.Error())
+			return purged, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for i := range stale {
+			ok, err := s.discardPending(ctx, &stale[i])
+			if err != nil {
+				log.Error(err.Error())
+				return purged, fmt.Errorf("%s: %w", op, err)
+			}
+			if ok {
+				purged++
+			}
+		}
+
+		if len(stale) < _purgeBatchSize {
+			break
+		}
+	}
+
+	if purged > 0 {
+		log.Info("stale pending uploads purged", slog.Int64("count", purged))
+	}
+	return purged, nil
+}
+
+// List returns a page of ready objects of the user
+func (s *MediaService) List(ctx context.Context, ownerID int64, limit, offset int) ([]entities.Media, error) {
+	if limit <= 0 {
+		limit = _defaultMediaLimit
+	}
+
+	return s.repo.ListByOwner(ctx, ownerID, limit, offset)
+}
+
+// Get returns an object with the URL to fetch it, private objects get a time limited URL
+func (s *MediaService) Get(ctx context.Context, userID int64, id string) (*entities.MediaResponse, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrMediaNotFound
+	}
+
+	m, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.read(ctx, userID, m)
+}
+
+// DownloadURL returns the URL to fetch the object stored under the key
+func (s *MediaService) DownloadURL(ctx context.Context, userID int64, key string) (*entities.MediaResponse, error) {
+	m, err := s.repo.GetByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.read(ctx, userID, m)
+}
+
+// Delete removes an object and its metadata
+func (s *MediaService) Delete(ctx context.Context, userID int64, id string) error {
+	const op = "MediaService.Delete"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+		slog.String("media_id", id),
+	)
+
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrMediaNotFound
+	}
+
+	m, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.authorize(ctx, userID, m)
+	if err != nil {
+		return err
+	}
+
+	// The objects go first, a failed metadata delete can be retried while the reverse would leak them
+	for _, v := range m.Variants {
+		err = s.storage.Delete(ctx, v.Key)
+		if err != nil {
+			log.Error(err.Error())
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err = s.storage.Delete(ctx, m.Key)
+	if err != nil {
+		log.Error(err.Error())
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.repo.Delete(ctx, m.ID)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	log.Info("media deleted")
+	return nil
+}
+
+// read returns a ready object the user may read with its URL
+func (s *MediaService) read(ctx context.Context, userID int64, m *entities.Media) (*entities.MediaResponse, error) {
+	if m.Status != entities.MediaReady {
+		return nil, ErrMediaNotFound
+	}
+
+	err := s.authorize(ctx, userID, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withURL(ctx, m)
+}
+
+// authorize admits the owner of the object and users allowed to manage media
+func (s *MediaService) authorize(ctx context.Context, userID int64, m *entities.Media) error {
+	if m.OwnerID == userID {
+		return nil
+	}
+
+	resp, err := s.auth.CheckPermission(ctx, &authv1.CheckPermissionRequest{
+		UserId:     userID,
+		Permission: _mediaManagePermission,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !resp.Allowed {
+		return ErrMediaForbidden
+	}
+
+	return nil
+}
+
+func (s *MediaService) withURL(ctx context.Context, m *entities.Media) (*entities.MediaResponse, error) {
+	const op = "MediaService.withURL"
+
+	resp := &entities.MediaResponse{Media: *m}
+
+	url, expiresAt, err := s.objectURL(ctx, m, m.Key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	resp.URL = url
+	if !expiresAt.IsZero() {
+		resp.ExpiresAt = expiresAt.Unix()
+	}
+
+	for _, v := range m.Variants {
+		url, _, err := s.objectURL(ctx, m, v.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		resp.Thumbnails = append(resp.Thumbnails, entities.Thumbnail{
+			Name:   v.Name,
+			Width:  v.Width,
+			Height: v.Height,
+			URL:    url,
+		})
+	}
+
+	return resp, nil
+}
+
+// objectURL returns the URL of an object of the media, private objects get a time limited URL
+func (s *MediaService) objectURL(ctx context.Context, m *entities.Media, key string) (string, time.Time, error) {
+	if m.Visibility == entities.MediaPublic {
+		return s.storage.URL(key), time.Time{}, nil
+	}
+
+	return s.storage.PresignGet(ctx, key)
+}
+
+// promote copies a presigned upload from its staging key to the key of the media.
+// The staged object is read once, the checks and the copy see the same content.
+// Images are stored as processed, the upload with its metadata never reaches the key of the media.
+func (s *MediaService) promote(ctx context.Context, m *entities.Media, policy *upload.Policy) error {
+	body, obj, err := s.storage.Get(ctx, stagingKey(m))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// The presigned URL pins these headers, a different object was not uploaded through it
+	if obj.Size != m.Size || obj.ContentType != m.ContentType {
+		return ErrUploadMismatch
+	}
+
+	head, err := io.ReadAll(io.LimitReader(body, upload.DetectLimit))
+	if err != nil {
+		return err
+	}
+
+	detected, err := policy.DetectHeader(head)
+	if err != nil {
+		return err
+	}
+	if detected != m.ContentType {
+		return fmt.Errorf("%w: content is %s", ErrUploadMismatch, detected)
+	}
+
+	content := io.MultiReader(bytes.NewReader(head), body)
+	if policy.Images && imaging.Supports(m.ContentType) {
+		return s.process(ctx, m, content)
+	}
+
+	err = s.storage.Put(ctx, m.Key, content, m.Size, m.ContentType, base64Checksum(m.Checksum))
+	if errors.Is(err, storage.ErrContentMismatch) {
+		return fmt.Errorf("%w: %w", ErrUploadMismatch, err)
+	}
+
+	return err
+}
+
+// checkPresign validates a presigned upload and returns its allowed content type
+func checkPresign(policy *upload.Policy, req *entities.PresignUploadRequest) (string, error) {
+	err := policy.CheckBatch(req.Size)
+	if err != nil {
+		return "", err
+	}
+
+	err = policy.CheckName(req.Filename)
+	if err != nil {
+		return "", err
+	}
+
+	return policy.CheckType(req.ContentType)
+}
+
+func (s *MediaService) newMedia(ownerID int64, policy *upload.Policy, filename, contentType string, size int64, checksum string) *entities.Media {
+	id := uuid.New().String()
+
+	visibility := entities.MediaPrivate
+	if policy.Public {
+		visibility = entities.MediaPublic
+	}
+
+	return &entities.Media{
+		ID:          id,
+		OwnerID:     ownerID,
+		Category:    policy.Category,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    checksum,
+		Bucket:      s.storage.Name(),
+		Key:         fmt.Sprintf("%s/%d/%s", _userObjectsPrefix, ownerID, id),
+		Visibility:  visibility,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// discard removes a presigned upload that can't be completed
+func (s *MediaService) discard(m *entities.Media) {
+	_, err := s.discardPending(context.Background(), m)
+	if err != nil {
+		s.log.Error("failed to discard upload", slog.String("key", m.Key), slog.String("error", err.Error()))
+	}
+}
+
+// discardPending removes the metadata of a pending upload, its staged upload and whatever was copied from it.
+// The metadata goes first, an upload completed meanwhile keeps its objects.
+func (s *MediaService) discardPending(ctx context.Context, m *entities.Media) (bool, error) {
+	ok, err := s.repo.DeletePending(ctx, m.ID)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	s.deleteObject(stagingKey(m))
+	s.deleteObjects(m)
+	return true, nil
+}
+
+// deleteObjects removes the objects of media that has no usable metadata
+func (s *MediaService) deleteObjects(m *entities.Media) {
+	s.deleteVariants(m)
+	s.deleteObject(m.Key)
+}
+
+func (s *MediaService) deleteVariants(m *entities.Media) {
+	for _, v := range m.Variants {
+		s.deleteObject(v.Key)
+	}
+}
+
+// deleteObject removes an object that has no usable metadata
+func (s *MediaService) deleteObject(key string) {
+	err := s.storage.Delete(context.Background(), key)
+	if err != nil {
+		s.log.Error("failed to delete object", slog.String("key", key), slog.String("error", err.Error()))
+	}
+}
+
+// stagingKey is where the presigned upload of the media is sent
+func stagingKey(m *entities.Media) string {
+	return fmt.Sprintf("%s/%d/%s", _stagingObjectsPrefix, m.OwnerID, m.ID)
+}
+
+// base64Checksum converts a stored hex checksum to the base64 form the storage checks
+func base64Checksum(checksum string) string {
+	sum, err := hex.DecodeString(checksum)
+	if err != nil || len(sum) == 0 {
+		return ""
+	}
+
+	return base64.StdEncoding.EncodeToString(sum)
+}
+
+// sha256Hex hashes the content and rewinds it for the upload
+func sha256Hex(r io.ReadSeeker) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
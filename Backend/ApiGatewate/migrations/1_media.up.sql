@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS media(
+    id UUID PRIMARY KEY,
+    owner_id BIGINT NOT NULL,
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL DEFAULT '',
+    bucket VARCHAR(255) NOT NULL,
+    object_key VARCHAR(512) UNIQUE NOT NULL,
+    visibility VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS media_owner_created_at_idx ON media(owner_id, created_at DESC);
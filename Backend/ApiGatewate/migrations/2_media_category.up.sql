@@ -0,0 +1 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS category VARCHAR(32) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS media_pending_created_at_idx;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS media_pending_created_at_idx ON media(created_at) WHERE status = 'pending';
@@ -6,6 +6,9 @@ import (
 	"os/signal"
 	"syscall"
 
+	// Profile timezones are validated without relying on the system zoneinfo
+	_ "time/tzdata"
+
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/app"
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/config"
 )
@@ -1,15 +1,23 @@
 package app
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 
 	grpcapp "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/app/grpc"
+	janitorapp "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/app/janitor"
+	metricsapp "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/app/metrics"
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/config"
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/infra/notifier"
 	repository "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/infra/postgres"
 	redisrepo "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/infra/redis"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/identity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/jwt"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/secretbox"
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/pkg/postgres"
 	rds "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/pkg/redis"
@@ -17,6 +25,8 @@ import (
 
 type App struct {
 	db         *postgres.Postgres
+	janitor    *janitorapp.App
+	metrics    *metricsapp.App
 	GRPCServer *grpcapp.App
 }
 
@@ -42,23 +52,118 @@ func Run(
 	dbConnector := postgres.NewDBConnector(pg.Pool)
 	userRepo := repository.NewUserRepository(dbConnector)
 	roleRepo := repository.NewRoleRepository(dbConnector)
-	tokenRepo := repository.NewTokenRepository(dbConnector)
+	var tokenRepo usecase.TokenRepoI = repository.NewTokenRepository(dbConnector)
 	tgConnRepo := repository.NewTgConnectionRepository(dbConnector)
+	totpRepo := repository.NewTOTPRepository(dbConnector)
+	profileRepo := repository.NewProfileRepository(dbConnector)
 	redisRepo := redisrepo.NewRedisRepository(redis)
 
+	// Access token lookups are served from Redis in front of Postgres
+	if cfg.TokenCache.Enabled {
+		tokenRepo = redisrepo.NewTokenCache(log, tokenRepo, redis, cfg.TokenCache.TTL, cfg.TokenCache.FlushInterval)
+	}
+
+	// Token signer
+	var signer usecase.TokenSigner
+	if cfg.JWT.Enabled {
+		keySet, err := jwt.NewKeySet(log, cfg.JWT)
+		if err != nil {
+			slog.Error(fmt.Errorf("app - Run - jwt.NewKeySet: %w", err).Error())
+			os.Exit(1)
+		}
+		signer = keySet
+	}
+
+	// Notifier
+	var ntf usecase.Notifier = notifier.NewLogNotifier(log, cfg.Notifier.FilePath)
+	if cfg.Notifier.TelegramBotToken != "" {
+		ntf = notifier.NewTelegramNotifier(log, cfg.Notifier.TelegramAPIURL, cfg.Notifier.TelegramBotToken, tgConnRepo, ntf)
+	}
+
+	// TOTP secrets encryption
+	box, err := newSecretBox(cfg.TOTP.EncryptionKey)
+	if err != nil {
+		slog.Error(fmt.Errorf("app - Run - newSecretBox: %w", err).Error())
+		os.Exit(1)
+	}
+
 	// Usecase
-	auth := usecase.NewAuthService(log, userRepo, roleRepo, tokenRepo, tgConnRepo, redisRepo, entity.AuthCode(cfg.AuthCode))
+	tokenTTL := entity.TokenTTL{
+		AccessTokenTTL:     cfg.Auth.AccessTokenTTL,
+		AccessTokenIdleTTL: cfg.Auth.AccessTokenIdleTTL,
+		RefreshTokenTTL:    cfg.Auth.RefreshTokenTTL,
+	}
+	resetCode := entity.AuthCode{
+		Length: cfg.PasswordReset.CodeLength,
+		TTL:    cfg.PasswordReset.CodeTTL,
+	}
+	attemptLimit := entity.AttemptLimit(cfg.Attempts)
+	activation := entity.Activation(cfg.Activation)
+	totpPolicy := entity.TOTPPolicy{
+		Issuer:        cfg.TOTP.Issuer,
+		ChallengeTTL:  cfg.TOTP.ChallengeTTL,
+		RequiredRoles: cfg.TOTP.RequiredRoles,
+	}
+	telegramLogin := entity.TelegramLogin{
+		BotToken:     cfg.Telegram.BotToken,
+		MaxAge:       cfg.Telegram.LoginMaxAge,
+		AutoRegister: cfg.Telegram.AutoRegister,
+	}
+	serviceTokens := entity.ServiceTokenPolicy(cfg.ServiceToken)
+	profilePolicy := entity.ProfilePolicy(cfg.Profile)
+	auth := usecase.NewAuthService(log, userRepo, roleRepo, tokenRepo, tgConnRepo, redisRepo, entity.AuthCode(cfg.AuthCode), tokenTTL, signer, resetCode, ntf, attemptLimit, activation, totpRepo, box, totpPolicy, telegramLogin, serviceTokens, profileRepo, profilePolicy)
+
+	// Janitor
+	janitor := janitorapp.New(log, auth, cfg.Auth.CleanupInterval)
+	go janitor.Run()
+
+	// Metrics
+	metrics := metricsapp.New(log, cfg.Metrics.Port)
+	go func() {
+		err := metrics.Run()
+		if err != nil {
+			log.Error("metrics server failed", slog.String("error", err.Error()))
+		}
+	}()
+
+	// Caller identities signed by the gateway
+	if cfg.Identity.Secret == "" {
+		if cfg.Env != "local" && cfg.Env != "dev" {
+			slog.Error("app - Run - identity secret is required outside local and dev")
+			os.Exit(1)
+		}
+		log.Warn("identity secret is empty, calls carrying a caller identity will be rejected")
+	}
+	identityVerifier := identity.NewVerifier(cfg.Identity.Secret, cfg.Identity.MaxSkew)
 
 	// GRPC
-	gRPCServer := grpcapp.New(log, auth, cfg.GRPC.Port)
+	gRPCServer := grpcapp.New(log, auth, identityVerifier, cfg.GRPC.Port)
 
 	return &App{
 		db:         pg,
+		janitor:    janitor,
+		metrics:    metrics,
 		GRPCServer: gRPCServer,
 	}
 }
 
 func (s *App) Shutdown() {
 	defer s.db.Close()
+	defer s.janitor.Stop()
+	defer s.metrics.Stop()
 	defer s.GRPCServer.Stop()
 }
+
+// newSecretBox decodes the base64 key. There is no fallback key: secrets sealed with
+// a key that is lost on restart could never be decrypted again.
+func newSecretBox(encodedKey string) (*secretbox.Box, error) {
+	if encodedKey == "" {
+		return nil, errors.New("totp encryption key is empty")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("totp encryption key is not valid base64: %w", err)
+	}
+	return secretbox.New(key)
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 
 	authgrpc "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/controller/grpc"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/identity"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"google.golang.org/grpc"
@@ -23,6 +24,7 @@ type App struct {
 func New(
 	log *slog.Logger,
 	authService authgrpc.Auth,
+	identityVerifier *identity.Verifier,
 	port int,
 ) *App {
 	loggingOpts := []logging.Option{
@@ -42,6 +44,7 @@ func New(
 	gRPCServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
 		recovery.UnaryServerInterceptor(recoveryOpts...),
 		logging.UnaryServerInterceptor(InterceptorLogger(log), loggingOpts...),
+		identityVerifier.UnaryServerInterceptor(),
 	))
 
 	authgrpc.Register(gRPCServer, authService)
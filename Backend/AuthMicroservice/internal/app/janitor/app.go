@@ -0,0 +1,86 @@
+package janitorapp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const _defaultPurgeTimeout = 30 * time.Second
+
+type Purger interface {
+	PurgeExpiredTokens(ctx context.Context) (int64, error)
+}
+
+// App periodically removes expired records in the background
+type App struct {
+	log      *slog.Logger
+	purger   Purger
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+func New(
+	log *slog.Logger,
+	purger Purger,
+	interval time.Duration,
+) *App {
+	return &App{
+		log:      log,
+		purger:   purger,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Run blocks until Stop is called
+func (a *App) Run() {
+	const op = "janitorapp.Run"
+
+	log := a.log.With(slog.String("op", op))
+
+	defer close(a.done)
+
+	if a.interval <= 0 {
+		log.Info("janitor disabled")
+		<-a.stop
+		return
+	}
+
+	log.Info("janitor started", slog.Duration("interval", a.interval))
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.purge()
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+func (a *App) purge() {
+	ctx, cancel := context.WithTimeout(context.Background(), _defaultPurgeTimeout)
+	defer cancel()
+
+	// Errors are logged by the purger, the next tick will retry
+	_, _ = a.purger.PurgeExpiredTokens(ctx)
+}
+
+// Stop signals the janitor to exit and waits for the current run to finish
+func (a *App) Stop() {
+	const op = "janitorapp.Stop"
+
+	a.log.With(slog.String("op", op)).
+		Info("stopping janitor")
+
+	a.once.Do(func() { close(a.stop) })
+	<-a.done
+}
@@ -0,0 +1,69 @@
+package metricsapp
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const _defaultShutdownTimeout = 5 * time.Second
+
+// App serves the expvar counters at /debug/vars
+type App struct {
+	log    *slog.Logger
+	server *http.Server
+	port   int
+}
+
+func New(
+	log *slog.Logger,
+	port int,
+) *App {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return &App{
+		log: log,
+		server: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           mux,
+			ReadHeaderTimeout: _defaultShutdownTimeout,
+		},
+		port: port,
+	}
+}
+
+// Run blocks until Stop is called, a zero port disables the server
+func (a *App) Run() error {
+	const op = "metricsapp.Run"
+
+	if a.port == 0 {
+		a.log.Info("metrics server disabled")
+		return nil
+	}
+
+	a.log.Info("metrics server started", slog.Int("port", a.port))
+
+	err := a.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (a *App) Stop() {
+	const op = "metricsapp.Stop"
+
+	a.log.With(slog.String("op", op)).
+		Info("stopping metrics server", slog.Int("port", a.port))
+
+	ctx, cancel := context.WithTimeout(context.Background(), _defaultShutdownTimeout)
+	defer cancel()
+
+	_ = a.server.Shutdown(ctx)
+}
@@ -9,12 +9,25 @@ import (
 )
 
 type Config struct {
-	Env            string         `env:"ENV" env-default:"local"`
-	Database       DatabaseConfig `env-prefix:"DB_"`
-	GRPC           GRPCConfig     `env-prefix:"GRPC_"`
-	AuthCode       AuthCodeConfig `env-prefix:"AUTH_CODE_"`
-	Redis          RedisConfig    `env-prefix:"REDIS_"`
-	MigrationsPath string         `env:"MIGRATIONS_PATH" env-default:"./migrations"`
+	Env            string              `env:"ENV" env-default:"local"`
+	Database       DatabaseConfig      `env-prefix:"DB_"`
+	GRPC           GRPCConfig          `env-prefix:"GRPC_"`
+	AuthCode       AuthCodeConfig      `env-prefix:"AUTH_CODE_"`
+	Auth           AuthConfig          `env-prefix:"AUTH_"`
+	JWT            JWTConfig           `env-prefix:"JWT_"`
+	PasswordReset  PasswordResetConfig `env-prefix:"PASSWORD_RESET_"`
+	Attempts       AttemptsConfig      `env-prefix:"ATTEMPTS_"`
+	Activation     ActivationConfig    `env-prefix:"ACTIVATION_"`
+	TOTP           TOTPConfig          `env-prefix:"TOTP_"`
+	Telegram       TelegramConfig      `env-prefix:"TELEGRAM_"`
+	ServiceToken   ServiceTokenConfig  `env-prefix:"SERVICE_TOKEN_"`
+	Profile        ProfileConfig       `env-prefix:"PROFILE_"`
+	Notifier       NotifierConfig      `env-prefix:"NOTIFIER_"`
+	Redis          RedisConfig         `env-prefix:"REDIS_"`
+	TokenCache     TokenCacheConfig    `env-prefix:"TOKEN_CACHE_"`
+	Identity       IdentityConfig      `env-prefix:"IDENTITY_"`
+	Metrics        MetricsConfig       `env-prefix:"METRICS_"`
+	MigrationsPath string              `env:"MIGRATIONS_PATH" env-default:"./migrations"`
 }
 
 type GRPCConfig struct {
@@ -23,8 +36,70 @@ type GRPCConfig struct {
 }
 
 type AuthCodeConfig struct {
-	Length int           `env:"LENGTH" env-default:"6"`
-	TTL    time.Duration `env:"TTL" env-default:"300"`
+	Length      int           `env:"LENGTH" env-default:"6"`
+	TTL         time.Duration `env:"TTL" env-default:"300"`
+	MaxAttempts int           `env:"MAX_ATTEMPTS" env-default:"3"`
+}
+
+type AuthConfig struct {
+	AccessTokenTTL     time.Duration `env:"ACCESS_TOKEN_TTL" env-default:"15m"`
+	AccessTokenIdleTTL time.Duration `env:"ACCESS_TOKEN_IDLE_TTL" env-default:"15m"`
+	RefreshTokenTTL    time.Duration `env:"REFRESH_TOKEN_TTL" env-default:"720h"`
+	CleanupInterval    time.Duration `env:"CLEANUP_INTERVAL" env-default:"10m"`
+}
+
+type JWTConfig struct {
+	Enabled      bool   `env:"ENABLED" env-default:"false"`
+	Issuer       string `env:"ISSUER" env-default:"psyhoapp-auth"`
+	Algorithm    string `env:"ALGORITHM" env-default:"EdDSA"`
+	KeysPath     string `env:"KEYS_PATH"`
+	SigningKeyID string `env:"SIGNING_KEY_ID"`
+}
+
+type PasswordResetConfig struct {
+	CodeLength int           `env:"CODE_LENGTH" env-default:"6"`
+	CodeTTL    time.Duration `env:"CODE_TTL" env-default:"15m"`
+}
+
+type AttemptsConfig struct {
+	MaxAttempts int           `env:"MAX" env-default:"5"`
+	Window      time.Duration `env:"WINDOW" env-default:"15m"`
+	BaseLockout time.Duration `env:"BASE_LOCKOUT" env-default:"1m"`
+	MaxLockout  time.Duration `env:"MAX_LOCKOUT" env-default:"1h"`
+}
+
+type ActivationConfig struct {
+	Required   bool          `env:"REQUIRED" env-default:"true"`
+	CodeLength int           `env:"CODE_LENGTH" env-default:"6"`
+	CodeTTL    time.Duration `env:"CODE_TTL" env-default:"24h"`
+}
+
+type TOTPConfig struct {
+	// EncryptionKey is a base64 encoded 32 byte key sealing secrets at rest
+	EncryptionKey string        `env:"ENCRYPTION_KEY" env-required:"true"`
+	Issuer        string        `env:"ISSUER" env-default:"PsyhoApp"`
+	ChallengeTTL  time.Duration `env:"CHALLENGE_TTL" env-default:"5m"`
+	RequiredRoles []string      `env:"REQUIRED_ROLES" env-default:"psychologist,admin" env-separator:","`
+}
+
+type TelegramConfig struct {
+	BotToken     string        `env:"BOT_TOKEN"`
+	LoginMaxAge  time.Duration `env:"LOGIN_MAX_AGE" env-default:"10m"`
+	AutoRegister bool          `env:"AUTO_REGISTER" env-default:"true"`
+}
+
+type ServiceTokenConfig struct {
+	RotationOverlap time.Duration `env:"ROTATION_OVERLAP" env-default:"24h"`
+}
+
+type NotifierConfig struct {
+	FilePath         string `env:"FILE_PATH"`
+	TelegramBotToken string `env:"TELEGRAM_BOT_TOKEN"`
+	TelegramAPIURL   string `env:"TELEGRAM_API_URL" env-default:"https://api.telegram.org"`
+}
+
+type ProfileConfig struct {
+	AvatarURLPrefix string `env:"AVATAR_URL_PREFIX" env-default:""`
 }
 
 type DatabaseConfig struct {
@@ -37,6 +112,25 @@ type RedisConfig struct {
 	Password string `env-required:"true"    env:"PASSWORD"`
 }
 
+type TokenCacheConfig struct {
+	Enabled bool          `env:"ENABLED" env-default:"true"`
+	TTL     time.Duration `env:"TTL" env-default:"30s"`
+	// FlushInterval throttles writes of the token last usage to the database. The cleanup of
+	// idle tokens reads the database and may remove them up to this much early, so it
+	// should stay well below the access token idle ttl.
+	FlushInterval time.Duration `env:"FLUSH_INTERVAL" env-default:"1m"`
+}
+
+// IdentityConfig holds the secret shared with the gateway to verify caller identities
+type IdentityConfig struct {
+	Secret  string        `env:"SECRET"`
+	MaxSkew time.Duration `env:"MAX_SKEW" env-default:"30s"`
+}
+
+type MetricsConfig struct {
+	Port int `env:"PORT" env-default:"0"`
+}
+
 func MustLoad() *Config {
 	configPath := fetchConfigPath()
 	if configPath == "" {
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	services "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+	authv1 "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/proto/gen/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListUsers returns a page of users matching the filter
+func (s *serverAPI) ListUsers(ctx context.Context, req *authv1.ListUsersRequest) (*authv1.ListUsersResponse, error) {
+	if err := s.requirePermission(ctx, permUsersManage); err != nil {
+		return nil, err
+	}
+
+	if req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	switch req.Status {
+	case "", entity.StatusPending, entity.StatusActive, entity.StatusBlocked, entity.StatusDeleted:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown status")
+	}
+
+	filter := entity.UserFilter{
+		Limit:          int(req.Limit),
+		Role:           req.Role,
+		UsernamePrefix: req.UsernamePrefix,
+		Status:         req.Status,
+	}
+	if req.CreatedFrom != 0 {
+		filter.CreatedFrom = time.Unix(req.CreatedFrom, 0)
+	}
+	if req.CreatedTo != 0 {
+		filter.CreatedTo = time.Unix(req.CreatedTo, 0)
+	}
+
+	page, err := s.auth.ListUsers(ctx, filter, req.Cursor)
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	resp := &authv1.ListUsersResponse{
+		Users:      make([]*authv1.User, 0, len(page.Users)),
+		NextCursor: page.NextCursor,
+	}
+	for i := range page.Users {
+		resp.Users = append(resp.Users, userToGRPC(&page.Users[i]))
+	}
+
+	return resp, nil
+}
+
+// GetUser returns a user by ID
+func (s *serverAPI) GetUser(ctx context.Context, req *authv1.GetUserRequest) (*authv1.GetUserResponse, error) {
+	if err := s.requirePermission(ctx, permUsersManage); err != nil {
+		return nil, err
+	}
+
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user ID is required")
+	}
+
+	user, err := s.auth.GetUser(ctx, int(req.UserId))
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &authv1.GetUserResponse{
+		User: userToGRPC(user),
+	}, nil
+}
+
+// DeleteUser deletes a user together with all of their tokens
+func (s *serverAPI) DeleteUser(ctx context.Context, req *authv1.DeleteUserRequest) (*authv1.DeleteUserResponse, error) {
+	if err := s.requirePermission(ctx, permUsersManage); err != nil {
+		return nil, err
+	}
+
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user ID is required")
+	}
+
+	err := s.auth.DeleteUser(ctx, int(req.UserId))
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &authv1.DeleteUserResponse{
+		Success: true,
+	}, nil
+}
+
+// BlockUser blocks a user and revokes all of their sessions
+func (s *serverAPI) BlockUser(ctx context.Context, req *authv1.BlockUserRequest) (*authv1.BlockUserResponse, error) {
+	if err := s.requirePermission(ctx, permUsersManage); err != nil {
+		return nil, err
+	}
+
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user ID is required")
+	}
+
+	err := s.auth.BlockUser(ctx, int(req.UserId))
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &authv1.BlockUserResponse{
+		Success: true,
+	}, nil
+}
+
+// UnblockUser lifts a block of a user
+func (s *serverAPI) UnblockUser(ctx context.Context, req *authv1.UnblockUserRequest) (*authv1.UnblockUserResponse, error) {
+	if err := s.requirePermission(ctx, permUsersManage); err != nil {
+		return nil, err
+	}
+
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user ID is required")
+	}
+
+	err := s.auth.UnblockUser(ctx, int(req.UserId))
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &authv1.UnblockUserResponse{
+		Success: true,
+	}, nil
+}
+
+// adminError maps user management errors to gRPC statuses
+func adminError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrAccountNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	case errors.Is(err, services.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, "invalid cursor")
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func userToGRPC(user *entity.User) *authv1.User {
+	return &authv1.User{
+		Id:        int64(user.ID),
+		Username:  user.Username,
+		Role:      user.Role,
+		Blocked:   user.Status == entity.StatusBlocked,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt.Unix(),
+		UpdatedAt: user.UpdatedAt.Unix(),
+	}
+}
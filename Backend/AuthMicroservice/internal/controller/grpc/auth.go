@@ -3,13 +3,17 @@ package controller
 import (
 	"context"
 	"errors"
+	"math"
+	"time"
 
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
 	services "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
 	authv1 "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/proto/gen/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 type serverAPI struct {
@@ -18,16 +22,54 @@ type serverAPI struct {
 }
 
 type Auth interface {
-	Login(ctx context.Context, username, password string) (*entity.LoginResponse, error)
-	Register(ctx context.Context, username, password string) error
+	Login(ctx context.Context, username, password string, client entity.ClientInfo) (*entity.LoginResponse, error)
+	Register(ctx context.Context, username, password string) (*entity.User, error)
+	ActivateAccount(ctx context.Context, username, code string) error
+	ResendActivationCode(ctx context.Context, username string) error
 	Logout(ctx context.Context, accessToken string) error
-	GenerateAuthCode(ctx context.Context, userID int) (string, error)
-	Verify(ctx context.Context, userId int, code string) (bool, error)
-	GenerateServiceToken(ctx context.Context, serviceName string) (string, error)
+	Refresh(ctx context.Context, refreshToken string, client entity.ClientInfo) (*entity.LoginResponse, error)
+	GenerateAuthCode(ctx context.Context, serviceToken string, tgUserID int) (string, error)
+	Verify(ctx context.Context, accessToken, code string) (bool, error)
+	GenerateServiceToken(ctx context.Context, serviceName string, scopes []string, ttl time.Duration) (*entity.SerivceToken, error)
+	RotateServiceToken(ctx context.Context, serviceName string, overlap, ttl time.Duration) (*entity.SerivceToken, error)
+	RevokeServiceToken(ctx context.Context, id int) error
+	ListServiceTokens(ctx context.Context) ([]entity.SerivceToken, error)
 	GetRole(ctx context.Context, userID int) (string, error)
-	SetRole(ctx context.Context, userID int, role string) error
-	CheckAccessToken(ctx context.Context, accessToken string) (int, error)
-	CheckServiceToken(ctx context.Context, serviceToken string) (bool, error)
+	SetRole(ctx context.Context, userID int, role string, revokeSessions bool) error
+	CheckAccessToken(ctx context.Context, accessToken string) (int, string, error)
+	CheckServiceToken(ctx context.Context, serviceToken string) (*entity.SerivceToken, error)
+	GetJWKS(ctx context.Context) ([]entity.JWK, error)
+	ListSessions(ctx context.Context, accessToken string) ([]entity.Session, error)
+	RevokeSession(ctx context.Context, accessToken string, sessionID int) error
+	RevokeAllSessions(ctx context.Context, accessToken string, keepCurrent bool) error
+	ChangePassword(ctx context.Context, accessToken, oldPassword, newPassword string, revokeOtherSessions bool) error
+	RequestPasswordReset(ctx context.Context, username string) error
+	ConfirmPasswordReset(ctx context.Context, username, code, newPassword string) error
+	CheckPermission(ctx context.Context, userID int, permission string) (bool, error)
+	ListRoles(ctx context.Context) ([]entity.Role, error)
+	CreateRole(ctx context.Context, title string) (*entity.Role, error)
+	DeleteRole(ctx context.Context, title string) error
+	ListPermissions(ctx context.Context) ([]entity.Permission, error)
+	CreatePermission(ctx context.Context, title, description string) (*entity.Permission, error)
+	DeletePermission(ctx context.Context, title string) error
+	GrantPermission(ctx context.Context, role, permission string) error
+	RevokePermission(ctx context.Context, role, permission string) error
+	ListUsers(ctx context.Context, filter entity.UserFilter, cursor string) (*entity.UserPage, error)
+	GetUser(ctx context.Context, userID int) (*entity.User, error)
+	DeleteUser(ctx context.Context, userID int) error
+	BlockUser(ctx context.Context, userID int) error
+	UnblockUser(ctx context.Context, userID int) error
+	EnrollTOTP(ctx context.Context, accessToken string) (*entity.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, accessToken, code string) error
+	DisableTOTP(ctx context.Context, accessToken, code string) error
+	LoginVerifyTOTP(ctx context.Context, challengeID, code string) (*entity.LoginResponse, error)
+	LoginWithTelegram(ctx context.Context, auth entity.TelegramAuth, client entity.ClientInfo) (*entity.LoginResponse, error)
+	GetTelegramConnection(ctx context.Context, accessToken string) (*entity.TgConnection, error)
+	UnlinkTelegram(ctx context.Context, accessToken string) error
+	RelinkTelegram(ctx context.Context, accessToken, code string) error
+	ResolveTelegramUser(ctx context.Context, serviceToken string, tgUserID int) (int, error)
+	GetMe(ctx context.Context, accessToken string) (*entity.Me, error)
+	UpdateProfile(ctx context.Context, accessToken string, update entity.ProfileUpdate) (*entity.Profile, error)
 }
 
 func Register(gRPCServer *grpc.Server, auth Auth) {
@@ -40,22 +82,24 @@ func (s *serverAPI) Login(ctx context.Context, req *authv1.LoginRequest) (*authv
 		return nil, status.Error(codes.InvalidArgument, "username and password are required")
 	}
 
-	resp, err := s.auth.Login(ctx, req.Username, req.Password)
+	client := entity.ClientInfo{UserAgent: req.UserAgent, IP: req.Ip}
+	resp, err := s.auth.Login(ctx, req.Username, req.Password, client)
 	if err != nil {
 		switch {
+		case errors.Is(err, services.ErrTooManyAttempts):
+			return nil, lockedError(err)
 		case errors.Is(err, services.ErrAccountNotFound):
 			return nil, status.Error(codes.NotFound, "account not found")
 		case errors.Is(err, services.ErrBadCredentials):
 			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		case errors.Is(err, services.ErrNotActivated), errors.Is(err, services.ErrAccountBlocked):
+			return nil, accountError(err)
 		default:
 			return nil, status.Error(codes.Internal, "internal server error")
 		}
 	}
 
-	return &authv1.LoginResponse{
-		Id:          int64(resp.ID),
-		AccessToken: resp.Token,
-	}, nil
+	return loginToGRPC(resp), nil
 }
 
 // Register implements the registration functionality
@@ -64,17 +108,64 @@ func (s *serverAPI) Register(ctx context.Context, req *authv1.RegisterRequest) (
 		return nil, status.Error(codes.InvalidArgument, "username and password are required")
 	}
 
-	err := s.auth.Register(ctx, req.Username, req.Password)
+	user, err := s.auth.Register(ctx, req.Username, req.Password)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrAccountAlreadyExists):
 			return nil, status.Error(codes.AlreadyExists, "account already exists")
+		case errors.Is(err, services.ErrReservedUsername):
+			return nil, status.Error(codes.InvalidArgument, "username is reserved")
 		default:
 			return nil, status.Error(codes.Internal, "internal server error")
 		}
 	}
 
 	return &authv1.RegisterResponse{
+		Success:            true,
+		ActivationRequired: user.Status == entity.StatusPending,
+	}, nil
+}
+
+// ActivateAccount activates a pending account with the code sent after registration
+func (s *serverAPI) ActivateAccount(ctx context.Context, req *authv1.ActivateAccountRequest) (*authv1.ActivateAccountResponse, error) {
+	if req.Username == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "username and code are required")
+	}
+
+	err := s.auth.ActivateAccount(ctx, req.Username, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTooManyAttempts):
+			return nil, lockedError(err)
+		case errors.Is(err, services.ErrVerificationFailed):
+			return nil, status.Error(codes.PermissionDenied, "invalid or expired activation code")
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &authv1.ActivateAccountResponse{
+		Success: true,
+	}, nil
+}
+
+// ResendActivationCode sends a new activation code to a pending account
+func (s *serverAPI) ResendActivationCode(ctx context.Context, req *authv1.ResendActivationCodeRequest) (*authv1.ResendActivationCodeResponse, error) {
+	if req.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	}
+
+	err := s.auth.ResendActivationCode(ctx, req.Username)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTooManyAttempts):
+			return nil, lockedError(err)
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &authv1.ResendActivationCodeResponse{
 		Success: true,
 	}, nil
 }
@@ -100,15 +191,45 @@ func (s *serverAPI) Logout(ctx context.Context, req *authv1.LogoutRequest) (*aut
 	}, nil
 }
 
+// Refresh implements the token refresh functionality
+func (s *serverAPI) Refresh(ctx context.Context, req *authv1.RefreshRequest) (*authv1.RefreshResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+	}
+
+	client := entity.ClientInfo{UserAgent: req.UserAgent, IP: req.Ip}
+	resp, err := s.auth.Refresh(ctx, req.RefreshToken, client)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTokenNotFound):
+			return nil, status.Error(codes.NotFound, "refresh token not found")
+		case errors.Is(err, services.ErrTokenExpired):
+			return nil, status.Error(codes.Unauthenticated, "refresh token expired")
+		case errors.Is(err, services.ErrNotActivated), errors.Is(err, services.ErrAccountBlocked):
+			return nil, accountError(err)
+		case errors.Is(err, services.ErrTokenReused):
+			return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected")
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &authv1.RefreshResponse{
+		Id:           int64(resp.ID),
+		AccessToken:  resp.Token,
+		RefreshToken: resp.RefreshToken,
+	}, nil
+}
+
 // GenerateAuthCode implements the auth code generation functionality
 func (s *serverAPI) GenerateAuthCode(ctx context.Context, req *authv1.GenerateAuthCodeRequest) (*authv1.GenerateAuthCodeResponse, error) {
-	if req.UserId == 0 {
-		return nil, status.Error(codes.InvalidArgument, "user ID is required")
+	if req.ServiceToken == "" || req.TgUserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "service token and tg user ID are required")
 	}
 
-	code, err := s.auth.GenerateAuthCode(ctx, int(req.UserId))
+	code, err := s.auth.GenerateAuthCode(ctx, req.ServiceToken, int(req.TgUserId))
 	if err != nil {
-		return nil, status.Error(codes.Internal, "internal server error")
+		return nil, serviceTokenError(err)
 	}
 
 	return &authv1.GenerateAuthCodeResponse{
@@ -118,17 +239,19 @@ func (s *serverAPI) GenerateAuthCode(ctx context.Context, req *authv1.GenerateAu
 
 // Verify implements the verification functionality
 func (s *serverAPI) Verify(ctx context.Context, req *authv1.VerifyRequest) (*authv1.VerifyResponse, error) {
-	if req.Code == "" {
-		return nil, status.Error(codes.InvalidArgument, "code is required")
+	if req.AccessToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token and code are required")
 	}
 
-	verified, err := s.auth.Verify(ctx, int(req.UserId), req.Code)
+	verified, err := s.auth.Verify(ctx, req.AccessToken, req.Code)
 	if err != nil {
 		switch {
+		case errors.Is(err, services.ErrTooManyAttempts):
+			return nil, lockedError(err)
 		case errors.Is(err, services.ErrVerificationFailed):
 			return nil, status.Error(codes.InvalidArgument, "verification failed")
 		default:
-			return nil, status.Error(codes.Internal, "internal server error")
+			return nil, sessionError(err)
 		}
 	}
 
@@ -137,22 +260,6 @@ func (s *serverAPI) Verify(ctx context.Context, req *authv1.VerifyRequest) (*aut
 	}, nil
 }
 
-// GenerateServiceToken implements the service token generation functionality
-func (s *serverAPI) GenerateServiceToken(ctx context.Context, req *authv1.GenerateServiceTokenRequest) (*authv1.GenerateServiceTokenResponse, error) {
-	if req.ServiceName == "" {
-		return nil, status.Error(codes.InvalidArgument, "service name is required")
-	}
-
-	token, err := s.auth.GenerateServiceToken(ctx, req.ServiceName)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "internal server error")
-	}
-
-	return &authv1.GenerateServiceTokenResponse{
-		Token: token,
-	}, nil
-}
-
 // GetRole implements the get role functionality
 func (s *serverAPI) GetRole(ctx context.Context, req *authv1.GetRoleRequest) (*authv1.GetRoleResponse, error) {
 	if req.UserId == 0 {
@@ -171,11 +278,15 @@ func (s *serverAPI) GetRole(ctx context.Context, req *authv1.GetRoleRequest) (*a
 
 // SetRole implements the set role functionality
 func (s *serverAPI) SetRole(ctx context.Context, req *authv1.SetRoleRequest) (*authv1.SetRoleResponse, error) {
+	if err := s.requirePermission(ctx, permRolesAssign); err != nil {
+		return nil, err
+	}
+
 	if req.UserId == 0 || req.Role == "" {
 		return nil, status.Error(codes.InvalidArgument, "user ID and role are required")
 	}
 
-	err := s.auth.SetRole(ctx, int(req.UserId), req.Role)
+	err := s.auth.SetRole(ctx, int(req.UserId), req.Role, req.RevokeSessions)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidRole):
@@ -192,17 +303,22 @@ func (s *serverAPI) SetRole(ctx context.Context, req *authv1.SetRoleRequest) (*a
 	}, nil
 }
 
-// CheckAccessToken validates an access token and returns the associated user ID
+// CheckAccessToken validates an access token and returns the associated user ID and role
 func (s *serverAPI) CheckAccessToken(ctx context.Context, req *authv1.CheckAccessTokenRequest) (*authv1.CheckAccessTokenResponse, error) {
 	if req.AccessToken == "" {
 		return nil, status.Error(codes.InvalidArgument, "access token is required")
 	}
 
-	userID, err := s.auth.CheckAccessToken(ctx, req.AccessToken)
+	userID, role, err := s.auth.CheckAccessToken(ctx, req.AccessToken)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrTokenNotFound):
 			return nil, status.Error(codes.NotFound, "access token not found")
+		case errors.Is(err, services.ErrTokenExpired):
+			return nil, status.Error(codes.Unauthenticated, "access token expired")
+		case errors.Is(err, services.ErrNotActivated), errors.Is(err, services.ErrAccountBlocked),
+			errors.Is(err, services.ErrTOTPPending):
+			return nil, accountError(err)
 		default:
 			return nil, status.Error(codes.Internal, "internal server error")
 		}
@@ -210,21 +326,220 @@ func (s *serverAPI) CheckAccessToken(ctx context.Context, req *authv1.CheckAcces
 
 	return &authv1.CheckAccessTokenResponse{
 		UserId: int64(userID),
+		Role:   role,
 	}, nil
 }
 
-// CheckServiceToken validates a service token
-func (s *serverAPI) CheckServiceToken(ctx context.Context, req *authv1.CheckServiceTokenRequest) (*authv1.CheckServiceTokenResponse, error) {
-	if req.ServiceToken == "" {
-		return nil, status.Error(codes.InvalidArgument, "service token is required")
+// GetJWKS returns the public keys used to verify signed access tokens
+func (s *serverAPI) GetJWKS(ctx context.Context, req *authv1.GetJWKSRequest) (*authv1.GetJWKSResponse, error) {
+	keys, err := s.auth.GetJWKS(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
 	}
 
-	valid, err := s.auth.CheckServiceToken(ctx, req.ServiceToken)
+	resp := &authv1.GetJWKSResponse{
+		Keys: make([]*authv1.JWK, 0, len(keys)),
+	}
+	for _, k := range keys {
+		resp.Keys = append(resp.Keys, &authv1.JWK{
+			Kty: k.Kty,
+			Kid: k.Kid,
+			Alg: k.Alg,
+			Use: k.Use,
+			Crv: k.Crv,
+			X:   k.X,
+			N:   k.N,
+			E:   k.E,
+		})
+	}
+
+	return resp, nil
+}
+
+// ListSessions returns the active sessions of the access token owner
+func (s *serverAPI) ListSessions(ctx context.Context, req *authv1.ListSessionsRequest) (*authv1.ListSessionsResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	sessions, err := s.auth.ListSessions(ctx, req.AccessToken)
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	resp := &authv1.ListSessionsResponse{
+		Sessions: make([]*authv1.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &authv1.Session{
+			Id:         int64(session.ID),
+			UserAgent:  session.UserAgent,
+			Ip:         session.IP,
+			CreatedAt:  session.CreatedAt.Unix(),
+			LastUsedAt: session.LastUsedAt.Unix(),
+			Current:    session.Current,
+		})
+	}
+
+	return resp, nil
+}
+
+// RevokeSession revokes one session of the access token owner
+func (s *serverAPI) RevokeSession(ctx context.Context, req *authv1.RevokeSessionRequest) (*authv1.RevokeSessionResponse, error) {
+	if req.AccessToken == "" || req.SessionId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "access token and session ID are required")
+	}
+
+	err := s.auth.RevokeSession(ctx, req.AccessToken, int(req.SessionId))
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	return &authv1.RevokeSessionResponse{
+		Success: true,
+	}, nil
+}
+
+// RevokeAllSessions revokes every session of the access token owner
+func (s *serverAPI) RevokeAllSessions(ctx context.Context, req *authv1.RevokeAllSessionsRequest) (*authv1.RevokeAllSessionsResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	err := s.auth.RevokeAllSessions(ctx, req.AccessToken, req.KeepCurrent)
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	return &authv1.RevokeAllSessionsResponse{
+		Success: true,
+	}, nil
+}
+
+// ChangePassword replaces the password of the access token owner
+func (s *serverAPI) ChangePassword(ctx context.Context, req *authv1.ChangePasswordRequest) (*authv1.ChangePasswordResponse, error) {
+	if req.AccessToken == "" || req.OldPassword == "" || req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token, old and new passwords are required")
+	}
+
+	err := s.auth.ChangePassword(ctx, req.AccessToken, req.OldPassword, req.NewPassword, req.RevokeOtherSessions)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBadCredentials):
+			return nil, status.Error(codes.PermissionDenied, "invalid old password")
+		case errors.Is(err, services.ErrAccountNotFound):
+			return nil, status.Error(codes.NotFound, "account not found")
+		default:
+			return nil, sessionError(err)
+		}
+	}
+
+	return &authv1.ChangePasswordResponse{
+		Success: true,
+	}, nil
+}
+
+// RequestPasswordReset sends a password reset code to the user
+func (s *serverAPI) RequestPasswordReset(ctx context.Context, req *authv1.RequestPasswordResetRequest) (*authv1.RequestPasswordResetResponse, error) {
+	if req.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	}
+
+	err := s.auth.RequestPasswordReset(ctx, req.Username)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "internal server error")
 	}
 
-	return &authv1.CheckServiceTokenResponse{
-		Valid: valid,
+	return &authv1.RequestPasswordResetResponse{
+		Success: true,
 	}, nil
 }
+
+// ConfirmPasswordReset sets a new password using a reset code
+func (s *serverAPI) ConfirmPasswordReset(ctx context.Context, req *authv1.ConfirmPasswordResetRequest) (*authv1.ConfirmPasswordResetResponse, error) {
+	if req.Username == "" || req.Code == "" || req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "username, code and new password are required")
+	}
+
+	err := s.auth.ConfirmPasswordReset(ctx, req.Username, req.Code, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTooManyAttempts):
+			return nil, lockedError(err)
+		case errors.Is(err, services.ErrInvalidResetCode):
+			return nil, status.Error(codes.PermissionDenied, "invalid or expired reset code")
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &authv1.ConfirmPasswordResetResponse{
+		Success: true,
+	}, nil
+}
+
+// sessionError maps session management errors to gRPC statuses
+func sessionError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrTokenNotFound):
+		return status.Error(codes.Unauthenticated, "access token not found")
+	case errors.Is(err, services.ErrTokenExpired):
+		return status.Error(codes.Unauthenticated, "access token expired")
+	case errors.Is(err, services.ErrNotActivated), errors.Is(err, services.ErrAccountBlocked),
+		errors.Is(err, services.ErrTOTPPending):
+		return accountError(err)
+	case errors.Is(err, services.ErrSessionNotFound):
+		return status.Error(codes.NotFound, "session not found")
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+// accountError maps an inactive account or a session pending 2FA enrollment to a status
+// carrying the reason, so clients can tell them apart
+func accountError(err error) error {
+	var st *status.Status
+	var reason string
+	switch {
+	case errors.Is(err, services.ErrNotActivated):
+		st = status.New(codes.FailedPrecondition, "account is not activated")
+		reason = "ACCOUNT_NOT_ACTIVATED"
+	case errors.Is(err, services.ErrTOTPPending):
+		st = status.New(codes.PermissionDenied, "two-factor authentication must be enabled first")
+		reason = "TOTP_ENROLLMENT_REQUIRED"
+	default:
+		st = status.New(codes.PermissionDenied, "account is blocked")
+		reason = "ACCOUNT_BLOCKED"
+	}
+
+	detailed, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: "auth",
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// lockedError maps a lockout to ResourceExhausted with the retry delay attached
+func lockedError(err error) error {
+	st := status.New(codes.ResourceExhausted, "too many attempts, try again later")
+
+	var locked *services.LockedError
+	if !errors.As(err, &locked) {
+		return st.Err()
+	}
+
+	// Round up so clients never retry before the lockout ends
+	delay := time.Duration(math.Ceil(locked.RetryAfter.Seconds())) * time.Second
+	detailed, detailsErr := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(delay),
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
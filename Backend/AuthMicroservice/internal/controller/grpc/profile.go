@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	services "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+	authv1 "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/proto/gen/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetMe returns the account and profile of the token owner
+func (s *serverAPI) GetMe(ctx context.Context, req *authv1.GetMeRequest) (*authv1.GetMeResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	me, err := s.auth.GetMe(ctx, req.AccessToken)
+	if err != nil {
+		return nil, profileError(err)
+	}
+
+	resp := &authv1.GetMeResponse{
+		UserId:    int64(me.User.ID),
+		Username:  me.User.Username,
+		Role:      me.User.Role,
+		Status:    me.User.Status,
+		CreatedAt: me.User.CreatedAt.Unix(),
+		Profile:   profileToGRPC(&me.Profile),
+	}
+	if me.TgConnection != nil {
+		resp.TelegramLinked = true
+		resp.TgUserId = int64(me.TgConnection.TgUserID)
+	}
+
+	return resp, nil
+}
+
+// UpdateProfile changes the profile fields present in the request
+func (s *serverAPI) UpdateProfile(ctx context.Context, req *authv1.UpdateProfileRequest) (*authv1.UpdateProfileResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	profile, err := s.auth.UpdateProfile(ctx, req.AccessToken, entity.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarUrl,
+		Timezone:    req.Timezone,
+		Locale:      req.Locale,
+	})
+	if err != nil {
+		return nil, profileError(err)
+	}
+
+	return &authv1.UpdateProfileResponse{
+		Profile: profileToGRPC(profile),
+	}, nil
+}
+
+func profileToGRPC(profile *entity.Profile) *authv1.Profile {
+	var updatedAt int64
+	if !profile.UpdatedAt.IsZero() {
+		updatedAt = profile.UpdatedAt.Unix()
+	}
+
+	return &authv1.Profile{
+		DisplayName: profile.DisplayName,
+		AvatarUrl:   profile.AvatarURL,
+		Timezone:    profile.Timezone,
+		Locale:      profile.Locale,
+		UpdatedAt:   updatedAt,
+	}
+}
+
+// profileError maps profile errors to gRPC statuses
+func profileError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidProfile):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return sessionError(err)
+	}
+}
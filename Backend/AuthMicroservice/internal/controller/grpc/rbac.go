@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/identity"
+	services "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+	authv1 "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/proto/gen/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Permissions the admin methods require of their caller
+const (
+	permRolesAssign           = "roles:assign"
+	permRolesManage           = "roles:manage"
+	permServiceTokensGenerate = "service_tokens:generate"
+	permServiceTokensManage   = "service_tokens:manage"
+	permUsersManage           = "users:manage"
+)
+
+// requirePermission admits calls whose caller identity, signed by the gateway, is granted the permission.
+// The permission is checked against the current role of the user, not the role the gateway signed.
+func (s *serverAPI) requirePermission(ctx context.Context, permission string) error {
+	id, ok := identity.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "caller identity is required")
+	}
+
+	allowed, err := s.auth.CheckPermission(ctx, int(id.UserID), permission)
+	if err != nil {
+		return status.Error(codes.Internal, "internal server error")
+	}
+	if !allowed {
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	return nil
+}
+
+// CheckPermission reports whether the role of a user grants the permission
+func (s *serverAPI) CheckPermission(ctx context.Context, req *authv1.CheckPermissionRequest) (*authv1.CheckPermissionResponse, error) {
+	if req.UserId == 0 || req.Permission == "" {
+		return nil, status.Error(codes.InvalidArgument, "user ID and permission are required")
+	}
+
+	allowed, err := s.auth.CheckPermission(ctx, int(req.UserId), req.Permission)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &authv1.CheckPermissionResponse{
+		Allowed: allowed,
+	}, nil
+}
+
+// ListRoles returns every role with its permissions
+func (s *serverAPI) ListRoles(ctx context.Context, req *authv1.ListRolesRequest) (*authv1.ListRolesResponse, error) {
+	if err := s.requirePermission(ctx, permRolesManage); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.auth.ListRoles(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	resp := &authv1.ListRolesResponse{
+		Roles: make([]*authv1.Role, 0, len(roles)),
+	}
+	for i := range roles {
+		resp.Roles = append(resp.Roles, roleToGRPC(&roles[i]))
+	}
+
+	return resp, nil
+}
+
+// CreateRole creates a role without permissions
+func (s *serverAPI) CreateRole(ctx context.Context, req *authv1.CreateRoleRequest) (*authv1.CreateRoleResponse, error) {
+	if err := s.requirePermission(ctx, permRolesManage); err != nil {
+		return nil, err
+	}
+
+	if req.Title == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+
+	role, err := s.auth.CreateRole(ctx, req.Title)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authv1.CreateRoleResponse{
+		Role: roleToGRPC(role),
+	}, nil
+}
+
+// DeleteRole deletes a role that is not assigned to any user
+func (s *serverAPI) DeleteRole(ctx context.Context, req *authv1.DeleteRoleRequest) (*authv1.DeleteRoleResponse, error) {
+	if err := s.requirePermission(ctx, permRolesManage); err != nil {
+		return nil, err
+	}
+
+	if req.Title == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+
+	err := s.auth.DeleteRole(ctx, req.Title)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authv1.DeleteRoleResponse{
+		Success: true,
+	}, nil
+}
+
+// ListPermissions returns every known permission
+func (s *serverAPI) ListPermissions(ctx context.Context, req *authv1.ListPermissionsRequest) (*authv1.ListPermissionsResponse, error) {
+	if err := s.requirePermission(ctx, permRolesManage); err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.auth.ListPermissions(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	resp := &authv1.ListPermissionsResponse{
+		Permissions: make([]*authv1.Permission, 0, len(permissions)),
+	}
+	for i := range permissions {
+		resp.Permissions = append(resp.Permissions, permissionToGRPC(&permissions[i]))
+	}
+
+	return resp, nil
+}
+
+// CreatePermission creates a permission that can be granted to roles
+func (s *serverAPI) CreatePermission(ctx context.Context, req *authv1.CreatePermissionRequest) (*authv1.CreatePermissionResponse, error) {
+	if err := s.requirePermission(ctx, permRolesManage); err != nil {
+		return nil, err
+	}
+
+	if req.Title == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+
+	permission, err := s.auth.CreatePermission(ctx, req.Title, req.Description)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authv1.CreatePermissionResponse{
+		Permission: permissionToGRPC(permission),
+	}, nil
+}
+
+// DeletePermission deletes a permission and revokes it from every role
+func (s *serverAPI) DeletePermission(ctx context.Context, req *authv1.DeletePermissionRequest) (*authv1.DeletePermissionResponse, error) {
+	if err := s.requirePermission(ctx, permRolesManage); err != nil {
+		return nil, err
+	}
+
+	if req.Title == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+
+	err := s.auth.DeletePermission(ctx, req.Title)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authv1.DeletePermissionResponse{
+		Success: true,
+	}, nil
+}
+
+// GrantPermission grants a permission to a role
+func (s *serverAPI) GrantPermission(ctx context.Context, req *authv1.GrantPermissionRequest) (*authv1.GrantPermissionResponse, error) {
+	if err := s.requirePermission(ctx, permRolesManage); err != nil {
+		return nil, err
+	}
+
+	if req.Role == "" || req.Permission == "" {
+		return nil, status.Error(codes.InvalidArgument, "role and permission are required")
+	}
+
+	err := s.auth.GrantPermission(ctx, req.Role, req.Permission)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authv1.GrantPermissionResponse{
+		Success: true,
+	}, nil
+}
+
+// RevokePermission revokes a permission from a role
+func (s *serverAPI) RevokePermission(ctx context.Context, req *authv1.RevokePermissionRequest) (*authv1.RevokePermissionResponse, error) {
+	if err := s.requirePermission(ctx, permRolesManage); err != nil {
+		return nil, err
+	}
+
+	if req.Role == "" || req.Permission == "" {
+		return nil, status.Error(codes.InvalidArgument, "role and permission are required")
+	}
+
+	err := s.auth.RevokePermission(ctx, req.Role, req.Permission)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authv1.RevokePermissionResponse{
+		Success: true,
+	}, nil
+}
+
+// rbacError maps role and permission management errors to gRPC statuses
+func rbacError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidRole):
+		return status.Error(codes.NotFound, "role not found")
+	case errors.Is(err, services.ErrPermissionNotFound):
+		return status.Error(codes.NotFound, "permission not found")
+	case errors.Is(err, services.ErrRoleAlreadyExists):
+		return status.Error(codes.AlreadyExists, "role already exists")
+	case errors.Is(err, services.ErrPermissionExists):
+		return status.Error(codes.AlreadyExists, "permission already exists")
+	case errors.Is(err, services.ErrRoleInUse):
+		return status.Error(codes.FailedPrecondition, "role is assigned to users")
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func roleToGRPC(role *entity.Role) *authv1.Role {
+	return &authv1.Role{
+		Id:          int64(role.ID),
+		Title:       role.Title,
+		Permissions: role.Permissions,
+	}
+}
+
+func permissionToGRPC(permission *entity.Permission) *authv1.Permission {
+	return &authv1.Permission{
+		Id:          int64(permission.ID),
+		Title:       permission.Title,
+		Description: permission.Description,
+	}
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/identity"
+	authv1 "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/proto/gen/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// permissionAuth grants the permissions of a single user, any other method of Auth panics
+type permissionAuth struct {
+	Auth
+	userID      int
+	permissions map[string]bool
+	err         error
+	roleSet     bool
+}
+
+func (a *permissionAuth) CheckPermission(ctx context.Context, userID int, permission string) (bool, error) {
+	return userID == a.userID && a.permissions[permission], a.err
+}
+
+func (a *permissionAuth) SetRole(ctx context.Context, userID int, role string, revokeSessions bool) error {
+	a.roleSet = true
+	return nil
+}
+
+func TestAdminMethodsRequirePermission(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		permissions map[string]bool
+		err         error
+		want        codes.Code
+	}{
+		{
+			name: "no identity",
+			ctx:  context.Background(),
+			want: codes.Unauthenticated,
+		},
+		{
+			name:        "other permission",
+			ctx:         identity.WithIdentity(context.Background(), identity.Identity{UserID: 42, Role: "admin"}),
+			permissions: map[string]bool{permRolesManage: true},
+			want:        codes.PermissionDenied,
+		},
+		{
+			name:        "other user",
+			ctx:         identity.WithIdentity(context.Background(), identity.Identity{UserID: 7, Role: "admin"}),
+			permissions: map[string]bool{permRolesAssign: true},
+			want:        codes.PermissionDenied,
+		},
+		{
+			name:        "check fails",
+			ctx:         identity.WithIdentity(context.Background(), identity.Identity{UserID: 42, Role: "admin"}),
+			permissions: map[string]bool{permRolesAssign: true},
+			err:         errors.New("db is down"),
+			want:        codes.Internal,
+		},
+		{
+			name:        "granted",
+			ctx:         identity.WithIdentity(context.Background(), identity.Identity{UserID: 42, Role: "user"}),
+			permissions: map[string]bool{permRolesAssign: true},
+			want:        codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &permissionAuth{userID: 42, permissions: tt.permissions, err: tt.err}
+			s := &serverAPI{auth: auth}
+
+			_, err := s.SetRole(tt.ctx, &authv1.SetRoleRequest{UserId: 1, Role: "admin"})
+			if status.Code(err) != tt.want {
+				t.Fatalf("code = %v, want %v", status.Code(err), tt.want)
+			}
+			if auth.roleSet != (tt.want == codes.OK) {
+				t.Fatalf("role set = %v", auth.roleSet)
+			}
+		})
+	}
+}
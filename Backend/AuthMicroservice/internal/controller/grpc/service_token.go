@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	services "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+	authv1 "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/proto/gen/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GenerateServiceToken issues the first token of a service
+func (s *serverAPI) GenerateServiceToken(ctx context.Context, req *authv1.GenerateServiceTokenRequest) (*authv1.GenerateServiceTokenResponse, error) {
+	if err := s.requirePermission(ctx, permServiceTokensGenerate); err != nil {
+		return nil, err
+	}
+
+	if req.ServiceName == "" {
+		return nil, status.Error(codes.InvalidArgument, "service name is required")
+	}
+	if req.TtlSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl must not be negative")
+	}
+
+	token, err := s.auth.GenerateServiceToken(ctx, req.ServiceName, req.Scopes, time.Duration(req.TtlSeconds)*time.Second)
+	if err != nil {
+		return nil, serviceTokenError(err)
+	}
+
+	return &authv1.GenerateServiceTokenResponse{
+		Token:     token.Token,
+		ExpiresAt: unixOrZero(token.ExpiresAt),
+	}, nil
+}
+
+// RotateServiceToken issues a new token of a service, keeping the previous one valid for the overlap window
+func (s *serverAPI) RotateServiceToken(ctx context.Context, req *authv1.RotateServiceTokenRequest) (*authv1.RotateServiceTokenResponse, error) {
+	if err := s.requirePermission(ctx, permServiceTokensManage); err != nil {
+		return nil, err
+	}
+
+	if req.ServiceName == "" {
+		return nil, status.Error(codes.InvalidArgument, "service name is required")
+	}
+	if req.OverlapSeconds < 0 || req.TtlSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "overlap and ttl must not be negative")
+	}
+
+	token, err := s.auth.RotateServiceToken(ctx, req.ServiceName,
+		time.Duration(req.OverlapSeconds)*time.Second, time.Duration(req.TtlSeconds)*time.Second)
+	if err != nil {
+		return nil, serviceTokenError(err)
+	}
+
+	return &authv1.RotateServiceTokenResponse{
+		Token:     token.Token,
+		ExpiresAt: unixOrZero(token.ExpiresAt),
+	}, nil
+}
+
+// RevokeServiceToken invalidates a service token immediately
+func (s *serverAPI) RevokeServiceToken(ctx context.Context, req *authv1.RevokeServiceTokenRequest) (*authv1.RevokeServiceTokenResponse, error) {
+	if err := s.requirePermission(ctx, permServiceTokensManage); err != nil {
+		return nil, err
+	}
+
+	if req.Id == 0 {
+		return nil, status.Error(codes.InvalidArgument, "token ID is required")
+	}
+
+	err := s.auth.RevokeServiceToken(ctx, int(req.Id))
+	if err != nil {
+		return nil, serviceTokenError(err)
+	}
+
+	return &authv1.RevokeServiceTokenResponse{
+		Success: true,
+	}, nil
+}
+
+// ListServiceTokens returns every service token without the token values
+func (s *serverAPI) ListServiceTokens(ctx context.Context, req *authv1.ListServiceTokensRequest) (*authv1.ListServiceTokensResponse, error) {
+	if err := s.requirePermission(ctx, permServiceTokensManage); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.auth.ListServiceTokens(ctx)
+	if err != nil {
+		return nil, serviceTokenError(err)
+	}
+
+	resp := &authv1.ListServiceTokensResponse{
+		Tokens: make([]*authv1.ServiceToken, 0, len(tokens)),
+	}
+	for i := range tokens {
+		resp.Tokens = append(resp.Tokens, serviceTokenToGRPC(&tokens[i]))
+	}
+
+	return resp, nil
+}
+
+// CheckServiceToken validates a service token and returns its service and scopes
+func (s *serverAPI) CheckServiceToken(ctx context.Context, req *authv1.CheckServiceTokenRequest) (*authv1.CheckServiceTokenResponse, error) {
+	if req.ServiceToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "service token is required")
+	}
+
+	token, err := s.auth.CheckServiceToken(ctx, req.ServiceToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidServiceToken) {
+			return &authv1.CheckServiceTokenResponse{Valid: false}, nil
+		}
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &authv1.CheckServiceTokenResponse{
+		Valid:       true,
+		ServiceName: token.ServiceName,
+		Scopes:      token.Scopes,
+		ExpiresAt:   unixOrZero(token.ExpiresAt),
+	}, nil
+}
+
+func serviceTokenError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidServiceToken):
+		return status.Error(codes.PermissionDenied, "invalid service token")
+	case errors.Is(err, services.ErrScopeNotGranted):
+		return status.Error(codes.PermissionDenied, "service token scope is not granted")
+	case errors.Is(err, services.ErrInvalidScope):
+		return status.Error(codes.InvalidArgument, "unknown scope")
+	case errors.Is(err, services.ErrServiceTokenExists):
+		return status.Error(codes.AlreadyExists, "service already has a valid token, rotate it instead")
+	case errors.Is(err, services.ErrServiceTokenNotFound):
+		return status.Error(codes.NotFound, "service token not found")
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func serviceTokenToGRPC(token *entity.SerivceToken) *authv1.ServiceToken {
+	return &authv1.ServiceToken{
+		Id:          int64(token.ID),
+		ServiceName: token.ServiceName,
+		Scopes:      token.Scopes,
+		ExpiresAt:   unixOrZero(token.ExpiresAt),
+		CreatedAt:   token.CreatedAt.Unix(),
+	}
+}
+
+// unixOrZero converts an optional time to unix seconds, zero means none
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	services "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+	authv1 "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/proto/gen/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LoginWithTelegram implements login with a Telegram Login Widget payload
+func (s *serverAPI) LoginWithTelegram(ctx context.Context, req *authv1.LoginWithTelegramRequest) (*authv1.LoginResponse, error) {
+	if req.Id == 0 || req.AuthDate == 0 || req.Hash == "" {
+		return nil, status.Error(codes.InvalidArgument, "id, auth_date and hash are required")
+	}
+
+	auth := entity.TelegramAuth{
+		ID:        req.Id,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Username:  req.Username,
+		PhotoURL:  req.PhotoUrl,
+		AuthDate:  req.AuthDate,
+		Hash:      req.Hash,
+	}
+	client := entity.ClientInfo{UserAgent: req.UserAgent, IP: req.Ip}
+
+	resp, err := s.auth.LoginWithTelegram(ctx, auth, client)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTooManyAttempts):
+			return nil, lockedError(err)
+		case errors.Is(err, services.ErrTelegramLoginOff):
+			return nil, status.Error(codes.Unimplemented, "telegram login is not configured")
+		case errors.Is(err, services.ErrInvalidTelegramAuth):
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired telegram login")
+		case errors.Is(err, services.ErrAccountNotFound):
+			return nil, status.Error(codes.NotFound, "account not found")
+		case errors.Is(err, services.ErrNotActivated), errors.Is(err, services.ErrAccountBlocked):
+			return nil, accountError(err)
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return loginToGRPC(resp), nil
+}
+
+// GetTelegramConnection returns the Telegram account linked to the token owner
+func (s *serverAPI) GetTelegramConnection(ctx context.Context, req *authv1.GetTelegramConnectionRequest) (*authv1.GetTelegramConnectionResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	conn, err := s.auth.GetTelegramConnection(ctx, req.AccessToken)
+	if err != nil {
+		return nil, telegramError(err)
+	}
+
+	return &authv1.GetTelegramConnectionResponse{
+		TgUserId:  int64(conn.TgUserID),
+		LinkedAt:  conn.CreatedAt.Unix(),
+		UpdatedAt: conn.UpdatedAt.Unix(),
+	}, nil
+}
+
+// UnlinkTelegram removes the Telegram account linked to the token owner
+func (s *serverAPI) UnlinkTelegram(ctx context.Context, req *authv1.UnlinkTelegramRequest) (*authv1.UnlinkTelegramResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	err := s.auth.UnlinkTelegram(ctx, req.AccessToken)
+	if err != nil {
+		return nil, telegramError(err)
+	}
+
+	return &authv1.UnlinkTelegramResponse{
+		Success: true,
+	}, nil
+}
+
+// RelinkTelegram links the Telegram account that requested the verification code
+func (s *serverAPI) RelinkTelegram(ctx context.Context, req *authv1.RelinkTelegramRequest) (*authv1.RelinkTelegramResponse, error) {
+	if req.AccessToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token and code are required")
+	}
+
+	err := s.auth.RelinkTelegram(ctx, req.AccessToken, req.Code)
+	if err != nil {
+		return nil, telegramError(err)
+	}
+
+	return &authv1.RelinkTelegramResponse{
+		Success: true,
+	}, nil
+}
+
+// ResolveTelegramUser returns the user linked to a Telegram account, for services only
+func (s *serverAPI) ResolveTelegramUser(ctx context.Context, req *authv1.ResolveTelegramUserRequest) (*authv1.ResolveTelegramUserResponse, error) {
+	if req.ServiceToken == "" || req.TgUserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "service token and tg user ID are required")
+	}
+
+	userID, err := s.auth.ResolveTelegramUser(ctx, req.ServiceToken, int(req.TgUserId))
+	if err != nil {
+		return nil, telegramError(err)
+	}
+
+	return &authv1.ResolveTelegramUserResponse{
+		UserId: int64(userID),
+	}, nil
+}
+
+// telegramError maps Telegram connection errors to gRPC statuses
+func telegramError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrTooManyAttempts):
+		return lockedError(err)
+	case errors.Is(err, services.ErrTgConnNotFound):
+		return status.Error(codes.NotFound, "telegram account is not linked")
+	case errors.Is(err, services.ErrTelegramLinked):
+		return status.Error(codes.AlreadyExists, "telegram account is linked to another user")
+	case errors.Is(err, services.ErrVerificationFailed):
+		return status.Error(codes.PermissionDenied, "invalid or expired verification code")
+	case errors.Is(err, services.ErrInvalidServiceToken), errors.Is(err, services.ErrScopeNotGranted):
+		return serviceTokenError(err)
+	default:
+		return sessionError(err)
+	}
+}
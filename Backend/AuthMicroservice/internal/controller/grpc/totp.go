@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	services "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+	authv1 "github.com/Homyakadze14/PsyhoApp/AuthMicroservice/proto/gen/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EnrollTOTP starts two-factor enrollment of the token owner
+func (s *serverAPI) EnrollTOTP(ctx context.Context, req *authv1.EnrollTOTPRequest) (*authv1.EnrollTOTPResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	enrollment, err := s.auth.EnrollTOTP(ctx, req.AccessToken)
+	if err != nil {
+		return nil, totpError(err)
+	}
+
+	return &authv1.EnrollTOTPResponse{
+		OtpauthUri:    enrollment.URI,
+		Secret:        enrollment.Secret,
+		RecoveryCodes: enrollment.RecoveryCodes,
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication with the first code
+func (s *serverAPI) ConfirmTOTP(ctx context.Context, req *authv1.ConfirmTOTPRequest) (*authv1.ConfirmTOTPResponse, error) {
+	if req.AccessToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token and code are required")
+	}
+
+	err := s.auth.ConfirmTOTP(ctx, req.AccessToken, req.Code)
+	if err != nil {
+		return nil, totpError(err)
+	}
+
+	return &authv1.ConfirmTOTPResponse{
+		Success: true,
+	}, nil
+}
+
+// DisableTOTP turns two-factor authentication off
+func (s *serverAPI) DisableTOTP(ctx context.Context, req *authv1.DisableTOTPRequest) (*authv1.DisableTOTPResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	err := s.auth.DisableTOTP(ctx, req.AccessToken, req.Code)
+	if err != nil {
+		return nil, totpError(err)
+	}
+
+	return &authv1.DisableTOTPResponse{
+		Success: true,
+	}, nil
+}
+
+// LoginVerifyTOTP completes a login challenge
+func (s *serverAPI) LoginVerifyTOTP(ctx context.Context, req *authv1.LoginVerifyTOTPRequest) (*authv1.LoginResponse, error) {
+	if req.ChallengeId == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge ID and code are required")
+	}
+
+	resp, err := s.auth.LoginVerifyTOTP(ctx, req.ChallengeId, req.Code)
+	if err != nil {
+		return nil, totpError(err)
+	}
+
+	return loginToGRPC(resp), nil
+}
+
+// totpError maps two-factor errors to gRPC statuses
+func totpError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrTooManyAttempts):
+		return lockedError(err)
+	case errors.Is(err, services.ErrTOTPNotEnrolled):
+		return status.Error(codes.FailedPrecondition, "two-factor authentication is not enrolled")
+	case errors.Is(err, services.ErrTOTPAlreadyEnabled):
+		return status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	case errors.Is(err, services.ErrTOTPRequired):
+		return status.Error(codes.PermissionDenied, "two-factor authentication is required for this role")
+	case errors.Is(err, services.ErrInvalidTOTPCode):
+		return status.Error(codes.PermissionDenied, "invalid two-factor code")
+	case errors.Is(err, services.ErrChallengeNotFound):
+		return status.Error(codes.Unauthenticated, "login challenge not found or expired")
+	default:
+		return sessionError(err)
+	}
+}
+
+func loginToGRPC(resp *entity.LoginResponse) *authv1.LoginResponse {
+	return &authv1.LoginResponse{
+		Id:                     int64(resp.ID),
+		AccessToken:            resp.Token,
+		RefreshToken:           resp.RefreshToken,
+		ChallengeId:            resp.ChallengeID,
+		TotpEnrollmentRequired: resp.TOTPEnrollmentRequired,
+	}
+}
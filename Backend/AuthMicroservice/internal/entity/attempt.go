@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// AttemptLimit configures brute-force protection.
+// After MaxAttempts failures within Window the subject is locked out for BaseLockout,
+// doubling with every further failure up to MaxLockout.
+type AttemptLimit struct {
+	MaxAttempts int
+	Window      time.Duration
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
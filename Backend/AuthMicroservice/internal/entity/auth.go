@@ -1,6 +1,12 @@
 package entity
 
+// LoginResponse holds either issued tokens or, when a second factor
+// is required, the challenge to complete with LoginVerifyTOTP
 type LoginResponse struct {
-	ID    int    `json:"id"`
-	Token string `json:"access_token"`
+	ID           int    `json:"id"`
+	Token        string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ChallengeID  string `json:"challenge_id"`
+
+	TOTPEnrollmentRequired bool `json:"totp_enrollment_required"`
 }
@@ -1,8 +1,52 @@
 package entity
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 type AuthCode struct {
-	Length int
-	TTL    time.Duration
+	Length      int
+	TTL         time.Duration
+	MaxAttempts int
+}
+
+// AuthCodeRecord binds a verification code to the Telegram user it was issued for
+type AuthCodeRecord struct {
+	TgUserID     int
+	Service      string
+	AttemptsLeft int
+	CreatedAt    time.Time
+}
+
+// ParseAuthCodeRecord reads a record stored as a hash
+func ParseAuthCodeRecord(fields map[string]string) (*AuthCodeRecord, error) {
+	tgUserID, err := strconv.Atoi(fields["tg_user_id"])
+	if err != nil {
+		return nil, err
+	}
+
+	attemptsLeft, err := strconv.Atoi(fields["attempts_left"])
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, err := strconv.ParseInt(fields["created_at"], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthCodeRecord{
+		TgUserID:     tgUserID,
+		Service:      fields["service"],
+		AttemptsLeft: attemptsLeft,
+		CreatedAt:    time.Unix(createdAt, 0),
+	}, nil
+}
+
+// Activation configures account activation after registration
+type Activation struct {
+	Required   bool
+	CodeLength int
+	CodeTTL    time.Duration
 }
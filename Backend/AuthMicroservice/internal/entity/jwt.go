@@ -0,0 +1,23 @@
+package entity
+
+type TokenClaims struct {
+	ID        string `json:"jti"`
+	Subject   string `json:"sub"`
+	Role      string `json:"role,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	// TOTPPending is set on tokens only accepted to enroll two-factor authentication
+	TOTPPending bool `json:"totp_pending,omitempty"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// Profile holds the optional personal data of a user.
+// Users without a stored profile have an empty one.
+type Profile struct {
+	UserID      int       `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	Timezone    string    `json:"timezone"`
+	Locale      string    `json:"locale"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ProfileUpdate changes the fields of a profile that are not nil.
+// Empty strings clear a field.
+type ProfileUpdate struct {
+	DisplayName *string
+	AvatarURL   *string
+	Timezone    *string
+	Locale      *string
+}
+
+// ProfilePolicy configures which avatars a profile may reference
+type ProfilePolicy struct {
+	// AvatarURLPrefix restricts avatars to uploaded media, any http(s) URL is accepted when empty
+	AvatarURLPrefix string
+}
+
+// Me is the account of an access token owner
+type Me struct {
+	User         User
+	TgConnection *TgConnection
+	Profile      Profile
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+type Session struct {
+	ID         int       `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
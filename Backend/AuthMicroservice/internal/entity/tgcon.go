@@ -9,3 +9,21 @@ type TgConnection struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// TelegramAuth is a payload of the Telegram Login Widget
+type TelegramAuth struct {
+	ID        int64
+	FirstName string
+	LastName  string
+	Username  string
+	PhotoURL  string
+	AuthDate  int64
+	Hash      string
+}
+
+// TelegramLogin configures logging in with Telegram
+type TelegramLogin struct {
+	BotToken     string
+	MaxAge       time.Duration
+	AutoRegister bool
+}
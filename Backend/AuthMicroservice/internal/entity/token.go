@@ -1,19 +1,81 @@
 package entity
 
-import "time"
+import (
+	"slices"
+	"time"
+)
+
+// Scopes granted to service tokens
+const (
+	ScopeGenerateAuthCode = "auth:generate_code"
+	ScopeResolveTelegram  = "auth:resolve_tg"
+)
+
+// ServiceScopes lists every scope a service token may be granted
+var ServiceScopes = []string{
+	ScopeGenerateAuthCode,
+	ScopeResolveTelegram,
+}
 
 type SerivceToken struct {
-	ID          int       `json:"id"`
-	ServiceName string    `json:"service_name"`
-	Token       string    `json:"token"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int    `json:"id"`
+	ServiceName string `json:"service_name"`
+	TokenHash   string `json:"-"`
+	// Token is the plaintext value, it is only known right after the token is issued
+	Token     string     `json:"token,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Expired reports whether the token is no longer valid at the given time.
+// Tokens without an expiry never expire.
+func (t *SerivceToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// HasScope reports whether the token was granted the scope
+func (t *SerivceToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
 }
 
 type AccessToken struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	Token     string    `json:"access_token"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	TokenHash  string    `json:"-"`
+	FamilyID   string    `json:"family_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// TOTPPending marks a session of a user who must enable two-factor authentication,
+	// it is only accepted to enroll
+	TOTPPending bool `json:"totp_pending"`
+	// Role and Status of the owner are only filled by lookups by token
+	Role   string `json:"role,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+type RefreshToken struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	FamilyID  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	RotatedAt *time.Time `json:"rotated_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type TokenTTL struct {
+	AccessTokenTTL     time.Duration
+	AccessTokenIdleTTL time.Duration
+	RefreshTokenTTL    time.Duration
+}
+
+type ServiceTokenPolicy struct {
+	// RotationOverlap is how long the previous token stays valid after a rotation
+	RotationOverlap time.Duration
 }
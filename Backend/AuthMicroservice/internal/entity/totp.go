@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// TOTP is the second factor of a user, the secret is encrypted at rest
+// and recovery codes are stored as SHA-256 hashes
+type TOTP struct {
+	UserID        int        `json:"user_id"`
+	Secret        []byte     `json:"-"`
+	RecoveryCodes []string   `json:"-"`
+	ConfirmedAt   *time.Time `json:"confirmed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Enabled reports whether enrollment was confirmed with a valid code
+func (t *TOTP) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// TOTPEnrollment is returned once when a user starts enrolling
+type TOTPEnrollment struct {
+	URI           string
+	Secret        string
+	RecoveryCodes []string
+}
+
+// TOTPPolicy configures two-factor authentication
+type TOTPPolicy struct {
+	Issuer        string
+	ChallengeTTL  time.Duration
+	RequiredRoles []string
+}
+
+// LoginChallenge links a pending second factor check to the password login
+type LoginChallenge struct {
+	UserID int        `json:"user_id"`
+	Client ClientInfo `json:"client"`
+}
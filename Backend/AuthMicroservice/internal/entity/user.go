@@ -2,11 +2,37 @@ package entity
 
 import "time"
 
+// Account statuses
+const (
+	StatusPending = "pending"
+	StatusActive  = "active"
+	StatusBlocked = "blocked"
+	StatusDeleted = "deleted"
+)
+
 type User struct {
 	ID        int       `json:"id"`
 	Username  string    `json:"username"`
 	Password  string    `json:"password"`
 	Role      string    `json:"role"`
+	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// UserFilter selects a page of users ordered by ID.
+// Zero values disable the corresponding filter.
+type UserFilter struct {
+	AfterID        int
+	Limit          int
+	Role           string
+	Status         string
+	UsernamePrefix string
+	CreatedFrom    time.Time
+	CreatedTo      time.Time
+}
+
+type UserPage struct {
+	Users      []User
+	NextCursor string
+}
@@ -0,0 +1,56 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogNotifier writes notifications to the log and optionally appends them to a file.
+// It is meant for local development where no delivery channel is available.
+type LogNotifier struct {
+	log  *slog.Logger
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogNotifier(log *slog.Logger, path string) *LogNotifier {
+	return &LogNotifier{
+		log:  log,
+		path: path,
+	}
+}
+
+// Notify logs the message for the user
+func (n *LogNotifier) Notify(ctx context.Context, userID int, message string) error {
+	const op = "notifier.LogNotifier.Notify"
+
+	n.log.Info("notification",
+		slog.String("op", op),
+		slog.Int("user_id", userID),
+		slog.String("message", message),
+	)
+
+	if n.path == "" {
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s user_id=%d %s\n", time.Now().Format(time.RFC3339), userID, message)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+)
+
+const _defaultSendTimeout = 5 * time.Second
+
+type TgConnectionGetter interface {
+	GetByUserID(ctx context.Context, userID int) (*entity.TgConnection, error)
+}
+
+// TelegramNotifier delivers notifications to the Telegram account linked to the user.
+// Users without a telegram connection are notified through the fallback.
+type TelegramNotifier struct {
+	log      *slog.Logger
+	client   *http.Client
+	apiURL   string
+	botToken string
+	tgConn   TgConnectionGetter
+	fallback usecase.Notifier
+}
+
+func NewTelegramNotifier(
+	log *slog.Logger,
+	apiURL string,
+	botToken string,
+	tgConn TgConnectionGetter,
+	fallback usecase.Notifier,
+) *TelegramNotifier {
+	return &TelegramNotifier{
+		log:      log,
+		client:   &http.Client{Timeout: _defaultSendTimeout},
+		apiURL:   apiURL,
+		botToken: botToken,
+		tgConn:   tgConn,
+		fallback: fallback,
+	}
+}
+
+type sendMessageRequest struct {
+	ChatID int    `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+type sendMessageResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// Notify sends the message to the linked Telegram chat
+func (n *TelegramNotifier) Notify(ctx context.Context, userID int, message string) error {
+	const op = "notifier.TelegramNotifier.Notify"
+
+	tgConn, err := n.tgConn.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrTgConnNotFound) {
+			return n.fallback.Notify(ctx, userID, message)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	body, err := json.Marshal(sendMessageRequest{ChatID: tgConn.TgUserID, Text: message})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", n.apiURL, n.botToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// The request URL contains the bot token, keep it out of the logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	var result sendMessageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("%s: telegram: %s", op, result.Description)
+	}
+
+	n.log.Info("notification sent to telegram",
+		slog.String("op", op),
+		slog.Int("user_id", userID),
+	)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+type ProfileRepository struct {
+	postgres.DBConnector
+}
+
+func NewProfileRepository(pg postgres.DBConnector) *ProfileRepository {
+	return &ProfileRepository{pg}
+}
+
+// GetByUserID retrieves the profile of a user
+func (r *ProfileRepository) GetByUserID(ctx context.Context, userID int) (*entity.Profile, error) {
+	const op = "repositories.ProfileRepository.GetByUserID"
+
+	query := `
+		SELECT user_id, display_name, avatar_url, timezone, locale, created_at, updated_at
+		FROM profile
+		WHERE user_id = $1
+	`
+
+	var profile entity.Profile
+	err := r.QueryRow(ctx, query, userID).Scan(
+		&profile.UserID, &profile.DisplayName, &profile.AvatarURL, &profile.Timezone, &profile.Locale,
+		&profile.CreatedAt, &profile.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrProfileNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &profile, nil
+}
+
+// Upsert stores the profile of a user, creating it on the first update
+func (r *ProfileRepository) Upsert(ctx context.Context, profile *entity.Profile) error {
+	const op = "repositories.ProfileRepository.Upsert"
+
+	query := `
+		INSERT INTO profile(user_id, display_name, avatar_url, timezone, locale, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET display_name = EXCLUDED.display_name, avatar_url = EXCLUDED.avatar_url,
+			timezone = EXCLUDED.timezone, locale = EXCLUDED.locale, updated_at = EXCLUDED.updated_at
+		RETURNING created_at, updated_at
+	`
+
+	err := r.QueryRow(ctx, query,
+		profile.UserID, profile.DisplayName, profile.AvatarURL, profile.Timezone, profile.Locale, time.Now(),
+	).Scan(&profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
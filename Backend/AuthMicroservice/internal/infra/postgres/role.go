@@ -138,3 +138,190 @@ func (r *RoleRepository) GetAll(ctx context.Context) ([]entity.Role, error) {
 
 	return roles, nil
 }
+
+// CountUsers returns the number of users having the role
+func (r *RoleRepository) CountUsers(ctx context.Context, roleID int) (int, error) {
+	const op = "repositories.RoleRepository.CountUsers"
+
+	query := `SELECT COUNT(*) FROM "account" WHERE role_id = $1`
+
+	var count int
+	err := r.QueryRow(ctx, query, roleID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// CreatePermission creates a new permission
+func (r *RoleRepository) CreatePermission(ctx context.Context, title, description string) (*entity.Permission, error) {
+	const op = "repositories.RoleRepository.CreatePermission"
+
+	query := `
+		INSERT INTO permission(title, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, title, COALESCE(description, ''), created_at, updated_at
+	`
+
+	var permission entity.Permission
+	err := r.QueryRow(ctx, query, title, description, time.Now(), time.Now()).Scan(
+		&permission.ID, &permission.Title, &permission.Description,
+		&permission.CreatedAt, &permission.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &permission, nil
+}
+
+// GetPermissionByTitle retrieves a permission by title
+func (r *RoleRepository) GetPermissionByTitle(ctx context.Context, title string) (*entity.Permission, error) {
+	const op = "repositories.RoleRepository.GetPermissionByTitle"
+
+	query := `SELECT id, title, COALESCE(description, ''), created_at, updated_at FROM permission WHERE title = $1`
+
+	var permission entity.Permission
+	err := r.QueryRow(ctx, query, title).Scan(
+		&permission.ID, &permission.Title, &permission.Description,
+		&permission.CreatedAt, &permission.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &permission, nil
+}
+
+// GetAllPermissions retrieves all permissions
+func (r *RoleRepository) GetAllPermissions(ctx context.Context) ([]entity.Permission, error) {
+	const op = "repositories.RoleRepository.GetAllPermissions"
+
+	query := `SELECT id, title, COALESCE(description, ''), created_at, updated_at FROM permission ORDER BY id`
+
+	rows, err := r.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	permissions := make([]entity.Permission, 0)
+	for rows.Next() {
+		var permission entity.Permission
+		err := rows.Scan(
+			&permission.ID, &permission.Title, &permission.Description,
+			&permission.CreatedAt, &permission.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, nil
+}
+
+// DeletePermission removes a permission by ID
+func (r *RoleRepository) DeletePermission(ctx context.Context, id int) error {
+	const op = "repositories.RoleRepository.DeletePermission"
+
+	query := `DELETE FROM permission WHERE id = $1`
+
+	result, err := r.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result == 0 {
+		return fmt.Errorf("%s: permission with id %d not found", op, id)
+	}
+
+	return nil
+}
+
+// GetPermissionsByRoleID retrieves the permission titles granted to a role
+func (r *RoleRepository) GetPermissionsByRoleID(ctx context.Context, roleID int) ([]string, error) {
+	const op = "repositories.RoleRepository.GetPermissionsByRoleID"
+
+	query := `
+		SELECT p.title
+		FROM role_permission rp
+		JOIN permission p ON rp.permission_id = p.id
+		WHERE rp.role_id = $1
+		ORDER BY p.title
+	`
+
+	rows, err := r.Query(ctx, query, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	permissions := make([]string, 0)
+	for rows.Next() {
+		var permission string
+		err := rows.Scan(&permission)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, nil
+}
+
+// AddPermission grants a permission to a role
+func (r *RoleRepository) AddPermission(ctx context.Context, roleID, permissionID int) error {
+	const op = "repositories.RoleRepository.AddPermission"
+
+	query := `
+		INSERT INTO role_permission(role_id, permission_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err := r.Exec(ctx, query, roleID, permissionID, time.Now())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RemovePermission revokes a permission from a role
+func (r *RoleRepository) RemovePermission(ctx context.Context, roleID, permissionID int) error {
+	const op = "repositories.RoleRepository.RemovePermission"
+
+	query := `DELETE FROM role_permission WHERE role_id = $1 AND permission_id = $2`
+
+	_, err := r.Exec(ctx, query, roleID, permissionID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// HasPermission reports whether the role of a user grants the permission
+func (r *RoleRepository) HasPermission(ctx context.Context, userID int, permission string) (bool, error) {
+	const op = "repositories.RoleRepository.HasPermission"
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM "account" u
+			JOIN role_permission rp ON rp.role_id = u.role_id
+			JOIN permission p ON rp.permission_id = p.id
+			WHERE u.id = $1 AND p.title = $2
+		)
+	`
+
+	var allowed bool
+	err := r.QueryRow(ctx, query, userID, permission).Scan(&allowed)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return allowed, nil
+}
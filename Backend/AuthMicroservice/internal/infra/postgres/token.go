@@ -2,13 +2,18 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/tokenhash"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/pkg/postgres"
+	"github.com/jackc/pgx/v5"
 )
 
+// TokenRepository stores tokens as SHA-256 digests, plaintext values never reach the database
 type TokenRepository struct {
 	postgres.DBConnector
 }
@@ -17,24 +22,25 @@ func NewTokenRepository(pg postgres.DBConnector) *TokenRepository {
 	return &TokenRepository{pg}
 }
 
-// CreateServiceToken creates a new service token
-func (r *TokenRepository) CreateServiceToken(ctx context.Context, serviceName, token string) (*entity.SerivceToken, error) {
+// CreateServiceToken stores the digest of a new service token, a nil expiresAt never expires
+func (r *TokenRepository) CreateServiceToken(ctx context.Context, serviceName, token string, scopes []string, expiresAt *time.Time) (*entity.SerivceToken, error) {
 	const op = "repositories.TokenRepository.CreateServiceToken"
 
 	query := `
-		INSERT INTO service_token(service_name, token, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, service_name, token, created_at, updated_at
+		INSERT INTO service_token(service_name, token_hash, scopes, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, service_name, token_hash, scopes, expires_at, created_at, updated_at
 	`
 
 	var serviceToken entity.SerivceToken
-	err := r.QueryRow(ctx, query, serviceName, token, time.Now(), time.Now()).Scan(
-		&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.Token,
-		&serviceToken.CreatedAt, &serviceToken.UpdatedAt,
+	err := r.QueryRow(ctx, query, serviceName, tokenhash.Sum(token), scopes, expiresAt, time.Now(), time.Now()).Scan(
+		&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.TokenHash, &serviceToken.Scopes,
+		&serviceToken.ExpiresAt, &serviceToken.CreatedAt, &serviceToken.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	serviceToken.Token = token
 
 	return &serviceToken, nil
 }
@@ -43,50 +49,73 @@ func (r *TokenRepository) CreateServiceToken(ctx context.Context, serviceName, t
 func (r *TokenRepository) GetServiceTokenByID(ctx context.Context, id int) (*entity.SerivceToken, error) {
 	const op = "repositories.TokenRepository.GetServiceTokenByID"
 
-	query := `SELECT id, service_name, token, created_at, updated_at FROM service_token WHERE id = $1`
+	query := `
+		SELECT id, service_name, token_hash, scopes, expires_at, created_at, updated_at
+		FROM service_token
+		WHERE id = $1
+	`
 
 	var serviceToken entity.SerivceToken
 	err := r.QueryRow(ctx, query, id).Scan(
-		&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.Token,
-		&serviceToken.CreatedAt, &serviceToken.UpdatedAt,
+		&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.TokenHash, &serviceToken.Scopes,
+		&serviceToken.ExpiresAt, &serviceToken.CreatedAt, &serviceToken.UpdatedAt,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrServiceTokenNotFound
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return &serviceToken, nil
 }
 
-// GetServiceTokenByServiceName retrieves a service token by service name
+// GetServiceTokenByServiceName retrieves the newest service token of a service
 func (r *TokenRepository) GetServiceTokenByServiceName(ctx context.Context, serviceName string) (*entity.SerivceToken, error) {
 	const op = "repositories.TokenRepository.GetServiceTokenByServiceName"
 
-	query := `SELECT id, service_name, token, created_at, updated_at FROM service_token WHERE service_name = $1`
+	query := `
+		SELECT id, service_name, token_hash, scopes, expires_at, created_at, updated_at
+		FROM service_token
+		WHERE service_name = $1
+		ORDER BY id DESC
+		LIMIT 1
+	`
 
 	var serviceToken entity.SerivceToken
 	err := r.QueryRow(ctx, query, serviceName).Scan(
-		&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.Token,
-		&serviceToken.CreatedAt, &serviceToken.UpdatedAt,
+		&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.TokenHash, &serviceToken.Scopes,
+		&serviceToken.ExpiresAt, &serviceToken.CreatedAt, &serviceToken.UpdatedAt,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrServiceTokenNotFound
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return &serviceToken, nil
 }
 
-// GetServiceTokenByToken retrieves a service token by token value
+// GetServiceTokenByToken retrieves a service token by the digest of its value
 func (r *TokenRepository) GetServiceTokenByToken(ctx context.Context, token string) (*entity.SerivceToken, error) {
 	const op = "repositories.TokenRepository.GetServiceTokenByToken"
 
-	query := `SELECT id, service_name, token, created_at, updated_at FROM service_token WHERE token = $1`
+	query := `
+		SELECT id, service_name, token_hash, scopes, expires_at, created_at, updated_at
+		FROM service_token
+		WHERE token_hash = $1
+	`
 
 	var serviceToken entity.SerivceToken
-	err := r.QueryRow(ctx, query, token).Scan(
-		&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.Token,
-		&serviceToken.CreatedAt, &serviceToken.UpdatedAt,
+	err := r.QueryRow(ctx, query, tokenhash.Sum(token)).Scan(
+		&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.TokenHash, &serviceToken.Scopes,
+		&serviceToken.ExpiresAt, &serviceToken.CreatedAt, &serviceToken.UpdatedAt,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrServiceTokenNotFound
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -99,11 +128,12 @@ func (r *TokenRepository) UpdateServiceToken(ctx context.Context, serviceToken *
 
 	query := `
 		UPDATE service_token
-		SET service_name = $1, token = $2, updated_at = $3
-		WHERE id = $4
+		SET service_name = $1, token_hash = $2, scopes = $3, expires_at = $4, updated_at = $5
+		WHERE id = $6
 	`
 
-	result, err := r.Exec(ctx, query, serviceToken.ServiceName, serviceToken.Token, time.Now(), serviceToken.ID)
+	result, err := r.Exec(ctx, query, serviceToken.ServiceName, serviceToken.TokenHash, serviceToken.Scopes,
+		serviceToken.ExpiresAt, time.Now(), serviceToken.ID)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -127,7 +157,7 @@ func (r *TokenRepository) DeleteServiceToken(ctx context.Context, id int) error
 	}
 
 	if result == 0 {
-		return fmt.Errorf("%s: service token with id %d not found", op, id)
+		return usecase.ErrServiceTokenNotFound
 	}
 
 	return nil
@@ -137,7 +167,11 @@ func (r *TokenRepository) DeleteServiceToken(ctx context.Context, id int) error
 func (r *TokenRepository) GetAllServiceTokens(ctx context.Context) ([]entity.SerivceToken, error) {
 	const op = "repositories.TokenRepository.GetAllServiceTokens"
 
-	query := `SELECT id, service_name, token, created_at, updated_at FROM service_token ORDER BY id`
+	query := `
+		SELECT id, service_name, token_hash, scopes, expires_at, created_at, updated_at
+		FROM service_token
+		ORDER BY id
+	`
 
 	rows, err := r.Query(ctx, query)
 	if err != nil {
@@ -149,8 +183,8 @@ func (r *TokenRepository) GetAllServiceTokens(ctx context.Context) ([]entity.Ser
 	for rows.Next() {
 		var serviceToken entity.SerivceToken
 		err := rows.Scan(
-			&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.Token,
-			&serviceToken.CreatedAt, &serviceToken.UpdatedAt,
+			&serviceToken.ID, &serviceToken.ServiceName, &serviceToken.TokenHash, &serviceToken.Scopes,
+			&serviceToken.ExpiresAt, &serviceToken.CreatedAt, &serviceToken.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -161,20 +195,22 @@ func (r *TokenRepository) GetAllServiceTokens(ctx context.Context) ([]entity.Ser
 	return serviceTokens, nil
 }
 
-// CreateAccessToken creates a new access token
-func (r *TokenRepository) CreateAccessToken(ctx context.Context, userID int, token string) (*entity.AccessToken, error) {
+// CreateAccessToken creates a new access token within the given token family
+func (r *TokenRepository) CreateAccessToken(ctx context.Context, userID int, token, familyID string, client entity.ClientInfo, totpPending bool) (*entity.AccessToken, error) {
 	const op = "repositories.TokenRepository.CreateAccessToken"
 
 	query := `
-		INSERT INTO token(user_id, access_token, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, user_id, access_token, created_at, updated_at
+		INSERT INTO token(user_id, access_token_hash, family_id, user_agent, ip, last_used_at, created_at, updated_at, totp_pending)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, user_id, access_token_hash, family_id, user_agent, ip, last_used_at, created_at, updated_at, totp_pending
 	`
 
+	now := time.Now()
 	var accessToken entity.AccessToken
-	err := r.QueryRow(ctx, query, userID, token, time.Now(), time.Now()).Scan(
-		&accessToken.ID, &accessToken.UserID, &accessToken.Token,
-		&accessToken.CreatedAt, &accessToken.UpdatedAt,
+	err := r.QueryRow(ctx, query, userID, tokenhash.Sum(token), familyID, client.UserAgent, client.IP, now, now, now, totpPending).Scan(
+		&accessToken.ID, &accessToken.UserID, &accessToken.TokenHash, &accessToken.FamilyID,
+		&accessToken.UserAgent, &accessToken.IP, &accessToken.LastUsedAt,
+		&accessToken.CreatedAt, &accessToken.UpdatedAt, &accessToken.TOTPPending,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -187,12 +223,18 @@ func (r *TokenRepository) CreateAccessToken(ctx context.Context, userID int, tok
 func (r *TokenRepository) GetAccessTokenByID(ctx context.Context, id int) (*entity.AccessToken, error) {
 	const op = "repositories.TokenRepository.GetAccessTokenByID"
 
-	query := `SELECT id, user_id, access_token, created_at, updated_at FROM token WHERE id = $1`
+	query := `
+		SELECT id, user_id, access_token_hash, COALESCE(family_id, ''), COALESCE(user_agent, ''),
+			COALESCE(ip, ''), COALESCE(last_used_at, updated_at), created_at, updated_at, totp_pending
+		FROM token
+		WHERE id = $1
+	`
 
 	var accessToken entity.AccessToken
 	err := r.QueryRow(ctx, query, id).Scan(
-		&accessToken.ID, &accessToken.UserID, &accessToken.Token,
-		&accessToken.CreatedAt, &accessToken.UpdatedAt,
+		&accessToken.ID, &accessToken.UserID, &accessToken.TokenHash, &accessToken.FamilyID,
+		&accessToken.UserAgent, &accessToken.IP, &accessToken.LastUsedAt,
+		&accessToken.CreatedAt, &accessToken.UpdatedAt, &accessToken.TOTPPending,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -201,16 +243,27 @@ func (r *TokenRepository) GetAccessTokenByID(ctx context.Context, id int) (*enti
 	return &accessToken, nil
 }
 
-// GetAccessTokenByToken retrieves an access token by token value
+// GetAccessTokenByToken retrieves an access token by the digest of its value
+// together with the role and status of its owner
 func (r *TokenRepository) GetAccessTokenByToken(ctx context.Context, token string) (*entity.AccessToken, error) {
 	const op = "repositories.TokenRepository.GetAccessTokenByToken"
 
-	query := `SELECT id, user_id, access_token, created_at, updated_at FROM token WHERE access_token = $1`
+	query := `
+		SELECT t.id, t.user_id, t.access_token_hash, COALESCE(t.family_id, ''), COALESCE(t.user_agent, ''),
+			COALESCE(t.ip, ''), COALESCE(t.last_used_at, t.updated_at), t.created_at, t.updated_at, t.totp_pending,
+			r.title, u.status
+		FROM token t
+		JOIN "account" u ON t.user_id = u.id
+		JOIN role r ON u.role_id = r.id
+		WHERE t.access_token_hash = $1
+	`
 
 	var accessToken entity.AccessToken
-	err := r.QueryRow(ctx, query, token).Scan(
-		&accessToken.ID, &accessToken.UserID, &accessToken.Token,
-		&accessToken.CreatedAt, &accessToken.UpdatedAt,
+	err := r.QueryRow(ctx, query, tokenhash.Sum(token)).Scan(
+		&accessToken.ID, &accessToken.UserID, &accessToken.TokenHash, &accessToken.FamilyID,
+		&accessToken.UserAgent, &accessToken.IP, &accessToken.LastUsedAt,
+		&accessToken.CreatedAt, &accessToken.UpdatedAt, &accessToken.TOTPPending,
+		&accessToken.Role, &accessToken.Status,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -223,12 +276,18 @@ func (r *TokenRepository) GetAccessTokenByToken(ctx context.Context, token strin
 func (r *TokenRepository) GetAccessTokenByUserID(ctx context.Context, userID int) (*entity.AccessToken, error) {
 	const op = "repositories.TokenRepository.GetAccessTokenByUserID"
 
-	query := `SELECT id, user_id, access_token, created_at, updated_at FROM token WHERE user_id = $1`
+	query := `
+		SELECT id, user_id, access_token_hash, COALESCE(family_id, ''), COALESCE(user_agent, ''),
+			COALESCE(ip, ''), COALESCE(last_used_at, updated_at), created_at, updated_at, totp_pending
+		FROM token
+		WHERE user_id = $1
+	`
 
 	var accessToken entity.AccessToken
 	err := r.QueryRow(ctx, query, userID).Scan(
-		&accessToken.ID, &accessToken.UserID, &accessToken.Token,
-		&accessToken.CreatedAt, &accessToken.UpdatedAt,
+		&accessToken.ID, &accessToken.UserID, &accessToken.TokenHash, &accessToken.FamilyID,
+		&accessToken.UserAgent, &accessToken.IP, &accessToken.LastUsedAt,
+		&accessToken.CreatedAt, &accessToken.UpdatedAt, &accessToken.TOTPPending,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -243,11 +302,11 @@ func (r *TokenRepository) UpdateAccessToken(ctx context.Context, accessToken *en
 
 	query := `
 		UPDATE token
-		SET user_id = $1, access_token = $2, updated_at = $3
-		WHERE id = $4
+		SET user_id = $1, access_token_hash = $2, family_id = $3, updated_at = $4
+		WHERE id = $5
 	`
 
-	result, err := r.Exec(ctx, query, accessToken.UserID, accessToken.Token, time.Now(), accessToken.ID)
+	result, err := r.Exec(ctx, query, accessToken.UserID, accessToken.TokenHash, accessToken.FamilyID, time.Now(), accessToken.ID)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -259,6 +318,24 @@ func (r *TokenRepository) UpdateAccessToken(ctx context.Context, accessToken *en
 	return nil
 }
 
+// RenewAccessToken moves the last usage time of an access token to now
+func (r *TokenRepository) RenewAccessToken(ctx context.Context, id int) error {
+	const op = "repositories.TokenRepository.RenewAccessToken"
+
+	query := `UPDATE token SET last_used_at = $1 WHERE id = $2`
+
+	result, err := r.Exec(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result == 0 {
+		return fmt.Errorf("%s: access token with id %d not found", op, id)
+	}
+
+	return nil
+}
+
 // DeleteAccessToken removes an access token by ID
 func (r *TokenRepository) DeleteAccessToken(ctx context.Context, id int) error {
 	const op = "repositories.TokenRepository.DeleteAccessToken"
@@ -277,11 +354,109 @@ func (r *TokenRepository) DeleteAccessToken(ctx context.Context, id int) error {
 	return nil
 }
 
+// DeleteExpiredAccessTokens removes access tokens created before createdBefore
+// or last used before usedBefore. A zero time disables the corresponding condition.
+func (r *TokenRepository) DeleteExpiredAccessTokens(ctx context.Context, createdBefore, usedBefore time.Time) (int64, error) {
+	const op = "repositories.TokenRepository.DeleteExpiredAccessTokens"
+
+	if createdBefore.IsZero() && usedBefore.IsZero() {
+		return 0, nil
+	}
+
+	query := `
+		DELETE FROM token
+		WHERE ($1::timestamp IS NOT NULL AND created_at < $1)
+		   OR ($2::timestamp IS NOT NULL AND COALESCE(last_used_at, updated_at) < $2)
+	`
+
+	result, err := r.Exec(ctx, query, nullTime(createdBefore), nullTime(usedBefore))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// GetAccessTokensByUserID retrieves all access tokens of a user
+func (r *TokenRepository) GetAccessTokensByUserID(ctx context.Context, userID int) ([]entity.AccessToken, error) {
+	const op = "repositories.TokenRepository.GetAccessTokensByUserID"
+
+	query := `
+		SELECT id, user_id, access_token_hash, COALESCE(family_id, ''), COALESCE(user_agent, ''),
+			COALESCE(ip, ''), COALESCE(last_used_at, updated_at), created_at, updated_at, totp_pending
+		FROM token
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	accessTokens := make([]entity.AccessToken, 0)
+	for rows.Next() {
+		var accessToken entity.AccessToken
+		err := rows.Scan(
+			&accessToken.ID, &accessToken.UserID, &accessToken.TokenHash, &accessToken.FamilyID,
+			&accessToken.UserAgent, &accessToken.IP, &accessToken.LastUsedAt,
+			&accessToken.CreatedAt, &accessToken.UpdatedAt, &accessToken.TOTPPending,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		accessTokens = append(accessTokens, accessToken)
+	}
+
+	return accessTokens, nil
+}
+
+// DeleteAccessTokensByFamily removes the access tokens of a token family
+func (r *TokenRepository) DeleteAccessTokensByFamily(ctx context.Context, familyID string) error {
+	const op = "repositories.TokenRepository.DeleteAccessTokensByFamily"
+
+	query := `DELETE FROM token WHERE family_id = $1`
+
+	_, err := r.Exec(ctx, query, familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteUserTokens removes all access and refresh tokens of a user
+func (r *TokenRepository) DeleteUserTokens(ctx context.Context, userID int) error {
+	const op = "repositories.TokenRepository.DeleteUserTokens"
+
+	query := `DELETE FROM refresh_token WHERE user_id = $1`
+
+	_, err := r.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `DELETE FROM token WHERE user_id = $1`
+
+	_, err = r.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
 // GetAllAccessTokens retrieves all access tokens
 func (r *TokenRepository) GetAllAccessTokens(ctx context.Context) ([]entity.AccessToken, error) {
 	const op = "repositories.TokenRepository.GetAllAccessTokens"
 
-	query := `SELECT id, user_id, access_token, created_at, updated_at FROM token ORDER BY id`
+	query := `
+		SELECT id, user_id, access_token_hash, COALESCE(family_id, ''), COALESCE(user_agent, ''),
+			COALESCE(ip, ''), COALESCE(last_used_at, updated_at), created_at, updated_at, totp_pending
+		FROM token
+		ORDER BY id
+	`
 
 	rows, err := r.Query(ctx, query)
 	if err != nil {
@@ -293,8 +468,9 @@ func (r *TokenRepository) GetAllAccessTokens(ctx context.Context) ([]entity.Acce
 	for rows.Next() {
 		var accessToken entity.AccessToken
 		err := rows.Scan(
-			&accessToken.ID, &accessToken.UserID, &accessToken.Token,
-			&accessToken.CreatedAt, &accessToken.UpdatedAt,
+			&accessToken.ID, &accessToken.UserID, &accessToken.TokenHash, &accessToken.FamilyID,
+			&accessToken.UserAgent, &accessToken.IP, &accessToken.LastUsedAt,
+			&accessToken.CreatedAt, &accessToken.UpdatedAt, &accessToken.TOTPPending,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -304,3 +480,109 @@ func (r *TokenRepository) GetAllAccessTokens(ctx context.Context) ([]entity.Acce
 
 	return accessTokens, nil
 }
+
+// CreateRefreshToken creates a new refresh token within the given token family
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, userID int, familyID, token string, expiresAt time.Time) (*entity.RefreshToken, error) {
+	const op = "repositories.TokenRepository.CreateRefreshToken"
+
+	query := `
+		INSERT INTO refresh_token(user_id, family_id, token_hash, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, user_id, family_id, token_hash, rotated_at, expires_at, created_at, updated_at
+	`
+
+	var refreshToken entity.RefreshToken
+	err := r.QueryRow(ctx, query, userID, familyID, tokenhash.Sum(token), expiresAt, time.Now(), time.Now()).Scan(
+		&refreshToken.ID, &refreshToken.UserID, &refreshToken.FamilyID, &refreshToken.TokenHash,
+		&refreshToken.RotatedAt, &refreshToken.ExpiresAt, &refreshToken.CreatedAt, &refreshToken.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &refreshToken, nil
+}
+
+// GetRefreshTokenByToken retrieves a refresh token by the digest of its value
+func (r *TokenRepository) GetRefreshTokenByToken(ctx context.Context, token string) (*entity.RefreshToken, error) {
+	const op = "repositories.TokenRepository.GetRefreshTokenByToken"
+
+	query := `
+		SELECT id, user_id, family_id, token_hash, rotated_at, expires_at, created_at, updated_at
+		FROM refresh_token
+		WHERE token_hash = $1
+	`
+
+	var refreshToken entity.RefreshToken
+	err := r.QueryRow(ctx, query, tokenhash.Sum(token)).Scan(
+		&refreshToken.ID, &refreshToken.UserID, &refreshToken.FamilyID, &refreshToken.TokenHash,
+		&refreshToken.RotatedAt, &refreshToken.ExpiresAt, &refreshToken.CreatedAt, &refreshToken.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &refreshToken, nil
+}
+
+// RotateRefreshToken marks a refresh token as rotated.
+// It returns false if the token has already been rotated by someone else.
+func (r *TokenRepository) RotateRefreshToken(ctx context.Context, id int) (bool, error) {
+	const op = "repositories.TokenRepository.RotateRefreshToken"
+
+	query := `
+		UPDATE refresh_token
+		SET rotated_at = $1, updated_at = $1
+		WHERE id = $2 AND rotated_at IS NULL
+	`
+
+	result, err := r.Exec(ctx, query, time.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result == 1, nil
+}
+
+// DeleteTokenFamily removes all refresh and access tokens of a token family
+func (r *TokenRepository) DeleteTokenFamily(ctx context.Context, familyID string) error {
+	const op = "repositories.TokenRepository.DeleteTokenFamily"
+
+	query := `DELETE FROM refresh_token WHERE family_id = $1`
+
+	_, err := r.Exec(ctx, query, familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `DELETE FROM token WHERE family_id = $1`
+
+	_, err = r.Exec(ctx, query, familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredRefreshTokens removes refresh tokens that expired before the given time
+func (r *TokenRepository) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error) {
+	const op = "repositories.TokenRepository.DeleteExpiredRefreshTokens"
+
+	query := `DELETE FROM refresh_token WHERE expires_at < $1`
+
+	result, err := r.Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// nullTime maps a zero time to NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
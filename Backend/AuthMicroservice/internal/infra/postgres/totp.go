@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+type TOTPRepository struct {
+	postgres.DBConnector
+}
+
+func NewTOTPRepository(pg postgres.DBConnector) *TOTPRepository {
+	return &TOTPRepository{pg}
+}
+
+// Upsert stores a new unconfirmed secret of a user, replacing any previous enrollment
+func (r *TOTPRepository) Upsert(ctx context.Context, userID int, secret []byte, recoveryCodes []string) error {
+	const op = "repositories.TOTPRepository.Upsert"
+
+	query := `
+		INSERT INTO account_totp(user_id, secret, recovery_codes, confirmed_at, created_at, updated_at)
+		VALUES ($1, $2, $3, NULL, $4, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, recovery_codes = EXCLUDED.recovery_codes,
+			confirmed_at = NULL, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.Exec(ctx, query, userID, secret, recoveryCodes, time.Now())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetByUserID retrieves the second factor of a user
+func (r *TOTPRepository) GetByUserID(ctx context.Context, userID int) (*entity.TOTP, error) {
+	const op = "repositories.TOTPRepository.GetByUserID"
+
+	query := `
+		SELECT user_id, secret, recovery_codes, confirmed_at, created_at, updated_at
+		FROM account_totp
+		WHERE user_id = $1
+	`
+
+	var totp entity.TOTP
+	err := r.QueryRow(ctx, query, userID).Scan(
+		&totp.UserID, &totp.Secret, &totp.RecoveryCodes, &totp.ConfirmedAt, &totp.CreatedAt, &totp.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrTOTPNotEnrolled
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &totp, nil
+}
+
+// Confirm marks the enrollment of a user as confirmed
+func (r *TOTPRepository) Confirm(ctx context.Context, userID int) error {
+	const op = "repositories.TOTPRepository.Confirm"
+
+	query := `UPDATE account_totp SET confirmed_at = $1, updated_at = $1 WHERE user_id = $2`
+
+	result, err := r.Exec(ctx, query, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result == 0 {
+		return usecase.ErrTOTPNotEnrolled
+	}
+
+	return nil
+}
+
+// Delete removes the second factor of a user
+func (r *TOTPRepository) Delete(ctx context.Context, userID int) error {
+	const op = "repositories.TOTPRepository.Delete"
+
+	query := `DELETE FROM account_totp WHERE user_id = $1`
+
+	_, err := r.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode consumes a recovery code, reporting whether it was still unused
+func (r *TOTPRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	const op = "repositories.TOTPRepository.UseRecoveryCode"
+
+	query := `
+		UPDATE account_totp
+		SET recovery_codes = array_remove(recovery_codes, $1), updated_at = $2
+		WHERE user_id = $3 AND $1 = ANY(recovery_codes)
+	`
+
+	result, err := r.Exec(ctx, query, codeHash, time.Now(), userID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result > 0, nil
+}
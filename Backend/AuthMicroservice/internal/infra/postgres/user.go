@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
@@ -18,7 +19,7 @@ func NewUserRepository(pg postgres.DBConnector) *UserRepository {
 }
 
 // Create creates a new user with the default 'user' role
-func (r *UserRepository) Create(ctx context.Context, username, password string) (*entity.User, error) {
+func (r *UserRepository) Create(ctx context.Context, username, password, status string) (*entity.User, error) {
 	const op = "repositories.UserRepository.Create"
 
 	// First get the role ID for 'user' role
@@ -31,16 +32,16 @@ func (r *UserRepository) Create(ctx context.Context, username, password string)
 	}
 
 	query = `
-		INSERT INTO "account"(username, password, role_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, username, password, role_id, created_at, updated_at
+		INSERT INTO "account"(username, password, role_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, username, password, role_id, status, created_at, updated_at
 	`
 
 	var userID int
 	var createdAt, updatedAt time.Time
 
-	err = r.QueryRow(ctx, query, username, password, roleID, time.Now(), time.Now()).Scan(
-		&userID, &username, &password, &roleID, &createdAt, &updatedAt,
+	err = r.QueryRow(ctx, query, username, password, roleID, status, time.Now(), time.Now()).Scan(
+		&userID, &username, &password, &roleID, &status, &createdAt, &updatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -51,6 +52,7 @@ func (r *UserRepository) Create(ctx context.Context, username, password string)
 		Username:  username,
 		Password:  password,
 		Role:      defaultRole,
+		Status:    status,
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
 	}
@@ -63,7 +65,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*entity.User, err
 	const op = "repositories.UserRepository.GetByID"
 
 	query := `
-		SELECT u.id, u.username, u.password, r.title, u.created_at, u.updated_at
+		SELECT u.id, u.username, u.password, r.title, u.status, u.created_at, u.updated_at
 		FROM "account" u
 		JOIN role r ON u.role_id = r.id
 		WHERE u.id = $1
@@ -71,7 +73,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*entity.User, err
 
 	var user entity.User
 	err := r.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Username, &user.Password, &user.Role, &user.Status, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -85,7 +87,7 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*e
 	const op = "repositories.UserRepository.GetByUsername"
 
 	query := `
-		SELECT u.id, u.username, u.password, r.title, u.created_at, u.updated_at
+		SELECT u.id, u.username, u.password, r.title, u.status, u.created_at, u.updated_at
 		FROM "account" u
 		JOIN role r ON u.role_id = r.id
 		WHERE u.username = $1
@@ -93,7 +95,7 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*e
 
 	var user entity.User
 	err := r.QueryRow(ctx, query, username).Scan(
-		&user.ID, &user.Username, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Username, &user.Password, &user.Role, &user.Status, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -165,7 +167,7 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]entity.User, error) {
 	const op = "repositories.UserRepository.GetAll"
 
 	query := `
-		SELECT u.id, u.username, u.password, r.title, u.created_at, u.updated_at
+		SELECT u.id, u.username, u.password, r.title, u.status, u.created_at, u.updated_at
 		FROM "account" u
 		JOIN role r ON u.role_id = r.id
 		ORDER BY u.id
@@ -181,7 +183,7 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]entity.User, error) {
 	for rows.Next() {
 		var user entity.User
 		err := rows.Scan(
-			&user.ID, &user.Username, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+			&user.ID, &user.Username, &user.Password, &user.Role, &user.Status, &user.CreatedAt, &user.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -191,3 +193,68 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]entity.User, error) {
 
 	return users, nil
 }
+
+// List retrieves a page of users matching the filter ordered by ID
+func (r *UserRepository) List(ctx context.Context, filter entity.UserFilter) ([]entity.User, error) {
+	const op = "repositories.UserRepository.List"
+
+	query := `
+		SELECT u.id, u.username, u.password, r.title, u.status, u.created_at, u.updated_at
+		FROM "account" u
+		JOIN role r ON u.role_id = r.id
+		WHERE u.id > $1
+		  AND ($2 = '' OR r.title = $2)
+		  AND ($3 = '' OR u.status = $3)
+		  AND ($4 = '' OR u.username LIKE $4 || '%' ESCAPE '\')
+		  AND ($5::timestamp IS NULL OR u.created_at >= $5)
+		  AND ($6::timestamp IS NULL OR u.created_at < $6)
+		ORDER BY u.id
+		LIMIT $7
+	`
+
+	rows, err := r.Query(ctx, query,
+		filter.AfterID, filter.Role, filter.Status, escapeLike(filter.UsernamePrefix),
+		nullTime(filter.CreatedFrom), nullTime(filter.CreatedTo), filter.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := make([]entity.User, 0)
+	for rows.Next() {
+		var user entity.User
+		err := rows.Scan(
+			&user.ID, &user.Username, &user.Password, &user.Role, &user.Status, &user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// SetStatus changes the account status of a user
+func (r *UserRepository) SetStatus(ctx context.Context, id int, status string) error {
+	const op = "repositories.UserRepository.SetStatus"
+
+	query := `UPDATE "account" SET status = $1, updated_at = $2 WHERE id = $3`
+
+	result, err := r.Exec(ctx, query, status, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result == 0 {
+		return fmt.Errorf("%s: user with id %d not found", op, id)
+	}
+
+	return nil
+}
+
+// escapeLike escapes LIKE wildcards so the value matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
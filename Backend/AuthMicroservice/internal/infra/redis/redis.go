@@ -67,3 +67,97 @@ func (r *RedisRepository) Expire(ctx context.Context, key string, expiration tim
 
 	return nil
 }
+
+// Incr atomically increments the counter at key, the expiration is set when the counter is created
+func (r *RedisRepository) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	const op = "repositories.RedisRepository.Incr"
+
+	res, err := r.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res == 1 {
+		err = r.redis.Expire(ctx, key, expiration).Err()
+		if err != nil {
+			return res, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return res, nil
+}
+
+// TTL returns the remaining time to live of key, a non-positive duration means no expiring key
+func (r *RedisRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+	const op = "repositories.RedisRepository.TTL"
+
+	res, err := r.redis.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+// setHashNXScript stores the hash unless the key exists.
+// ARGV holds the expiration in milliseconds followed by field/value pairs.
+var setHashNXScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 2))
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return 1
+`)
+
+// claimAttemptScript decrements the attempts counter of the hash and returns all fields.
+// The key is removed once no attempts are left.
+var claimAttemptScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+if redis.call('HINCRBY', KEYS[1], ARGV[1], -1) < 0 then
+	redis.call('DEL', KEYS[1])
+	return false
+end
+return redis.call('HGETALL', KEYS[1])
+`)
+
+// SetHashNX atomically stores the fields at key unless the key already exists
+func (r *RedisRepository) SetHashNX(ctx context.Context, key string, fields map[string]any, expiration time.Duration) (bool, error) {
+	const op = "repositories.RedisRepository.SetHashNX"
+
+	args := make([]any, 0, 1+2*len(fields))
+	args = append(args, expiration.Milliseconds())
+	for k, v := range fields {
+		args = append(args, k, v)
+	}
+
+	res, err := setHashNXScript.Run(ctx, r.redis, []string{key}, args...).Int()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res == 1, nil
+}
+
+// ClaimAttempt atomically takes one attempt from the counter field of the hash at key
+// and returns its fields. Exhausted and missing keys are reported as not found.
+func (r *RedisRepository) ClaimAttempt(ctx context.Context, key, counter string) (map[string]string, error) {
+	const op = "repositories.RedisRepository.ClaimAttempt"
+
+	res, err := claimAttemptScript.Run(ctx, r.redis, []string{key}, counter).StringSlice()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, usecase.ErrCacheNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	fields := make(map[string]string, len(res)/2)
+	for i := 0; i+1 < len(res); i += 2 {
+		fields[res[i]] = res[i+1]
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,302 @@
+package redisrepo
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/tokenhash"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/usecase"
+	"github.com/redis/go-redis/v9"
+)
+
+const _tokenCachePrefix = "token_cache:"
+
+// tokenCacheMetrics is published at /debug/vars when the metrics listener is enabled
+var tokenCacheMetrics = expvar.NewMap("token_cache")
+
+// renewCachedTokenScript moves last_used_at of the cached token referenced by the id key.
+// ARGV holds the entry key prefix, the new value and the flush interval.
+// It returns -1 when the token is not cached, 1 when the last usage stored in the
+// repository is older than the flush interval and 0 otherwise.
+var renewCachedTokenScript = redis.NewScript(`
+local digest = redis.call('GET', KEYS[1])
+if not digest then
+	return -1
+end
+local key = ARGV[1] .. digest
+if redis.call('EXISTS', key) == 0 then
+	return -1
+end
+redis.call('HSET', key, 'last_used_at', ARGV[2])
+local persisted = tonumber(redis.call('HGET', key, 'persisted_at'))
+if persisted and tonumber(ARGV[2]) - persisted < tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('HSET', key, 'persisted_at', ARGV[2])
+return 1
+`)
+
+// TokenCache is a read-through cache of access token lookups in front of a token repository.
+// Entries hold the token with the role and status of its owner, they are keyed by the token
+// digest and indexed by token ID, user and family, so every repository write that revokes
+// tokens also drops their entries. Role and status changes drop them with InvalidateUser.
+// The last usage is kept in the entry and written to the repository once per flush interval.
+type TokenCache struct {
+	usecase.TokenRepoI
+	log           *slog.Logger
+	redis         *redis.Client
+	ttl           time.Duration
+	flushInterval time.Duration
+}
+
+func NewTokenCache(log *slog.Logger, repo usecase.TokenRepoI, redis *redis.Client, ttl, flushInterval time.Duration) *TokenCache {
+	return &TokenCache{
+		TokenRepoI:    repo,
+		log:           log,
+		redis:         redis,
+		ttl:           ttl,
+		flushInterval: flushInterval,
+	}
+}
+
+// GetAccessTokenByToken returns the cached token, falling back to the repository on a miss
+func (c *TokenCache) GetAccessTokenByToken(ctx context.Context, token string) (*entity.AccessToken, error) {
+	const op = "repositories.TokenCache.GetAccessTokenByToken"
+
+	digest := tokenhash.Sum(token)
+
+	fields, err := c.redis.HGetAll(ctx, entryKey(digest)).Result()
+	if err != nil {
+		tokenCacheMetrics.Add("errors", 1)
+		c.log.Warn("token cache read failed", slog.String("op", op), slog.String("error", err.Error()))
+	}
+	if err == nil && len(fields) > 0 {
+		accessToken, err := parseCachedToken(fields)
+		if err == nil {
+			tokenCacheMetrics.Add("hits", 1)
+			return accessToken, nil
+		}
+		tokenCacheMetrics.Add("errors", 1)
+		c.log.Warn("malformed token cache entry", slog.String("op", op), slog.String("error", err.Error()))
+	}
+
+	tokenCacheMetrics.Add("misses", 1)
+
+	accessToken, err := c.TokenRepoI.GetAccessTokenByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.store(ctx, digest, accessToken)
+	if err != nil {
+		tokenCacheMetrics.Add("errors", 1)
+		c.log.Warn("token cache write failed", slog.String("op", op), slog.String("error", err.Error()))
+	}
+
+	return accessToken, nil
+}
+
+// RenewAccessToken moves the last usage time in the cached entry. The repository is only
+// written when the token is not cached or its stored last usage is older than the flush interval.
+func (c *TokenCache) RenewAccessToken(ctx context.Context, id int) error {
+	const op = "repositories.TokenCache.RenewAccessToken"
+
+	now := strconv.FormatInt(time.Now().UnixNano(), 10)
+	res, err := renewCachedTokenScript.Run(ctx, c.redis, []string{idKey(id)},
+		_tokenCachePrefix, now, c.flushInterval.Nanoseconds()).Int()
+	if err != nil {
+		tokenCacheMetrics.Add("errors", 1)
+		c.log.Warn("token cache renew failed", slog.String("op", op), slog.String("error", err.Error()))
+		res = -1
+	}
+	if res == 0 {
+		return nil
+	}
+
+	tokenCacheMetrics.Add("flushes", 1)
+	return c.TokenRepoI.RenewAccessToken(ctx, id)
+}
+
+// DeleteAccessToken removes the token and drops its cached entry
+func (c *TokenCache) DeleteAccessToken(ctx context.Context, id int) error {
+	err := c.TokenRepoI.DeleteAccessToken(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	c.invalidateID(ctx, id)
+	return nil
+}
+
+// DeleteAccessTokensByFamily removes the access tokens of a family and drops their cached entries
+func (c *TokenCache) DeleteAccessTokensByFamily(ctx context.Context, familyID string) error {
+	err := c.TokenRepoI.DeleteAccessTokensByFamily(ctx, familyID)
+	if err != nil {
+		return err
+	}
+
+	c.invalidateSet(ctx, familyKey(familyID))
+	return nil
+}
+
+// DeleteTokenFamily removes a token family and drops the cached entries of its access tokens
+func (c *TokenCache) DeleteTokenFamily(ctx context.Context, familyID string) error {
+	err := c.TokenRepoI.DeleteTokenFamily(ctx, familyID)
+	if err != nil {
+		return err
+	}
+
+	c.invalidateSet(ctx, familyKey(familyID))
+	return nil
+}
+
+// InvalidateUser drops the cached entries of a user whose role or status changed
+func (c *TokenCache) InvalidateUser(ctx context.Context, userID int) {
+	c.invalidateSet(ctx, userKey(userID))
+}
+
+// DeleteUserTokens removes all tokens of a user and drops their cached entries
+func (c *TokenCache) DeleteUserTokens(ctx context.Context, userID int) error {
+	err := c.TokenRepoI.DeleteUserTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	c.invalidateSet(ctx, userKey(userID))
+	return nil
+}
+
+func (c *TokenCache) store(ctx context.Context, digest string, accessToken *entity.AccessToken) error {
+	const op = "repositories.TokenCache.store"
+
+	_, err := c.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		key := entryKey(digest)
+		pipe.HSet(ctx, key,
+			"id", accessToken.ID,
+			"user_id", accessToken.UserID,
+			"family_id", accessToken.FamilyID,
+			"user_agent", accessToken.UserAgent,
+			"ip", accessToken.IP,
+			"last_used_at", accessToken.LastUsedAt.UnixNano(),
+			"created_at", accessToken.CreatedAt.UnixNano(),
+			"updated_at", accessToken.UpdatedAt.UnixNano(),
+			"totp_pending", accessToken.TOTPPending,
+			"role", accessToken.Role,
+			"status", accessToken.Status,
+			"persisted_at", accessToken.LastUsedAt.UnixNano(),
+		)
+		pipe.PExpire(ctx, key, c.ttl)
+		pipe.Set(ctx, idKey(accessToken.ID), digest, c.ttl)
+		pipe.SAdd(ctx, userKey(accessToken.UserID), digest)
+		pipe.PExpire(ctx, userKey(accessToken.UserID), c.ttl)
+		if accessToken.FamilyID != "" {
+			pipe.SAdd(ctx, familyKey(accessToken.FamilyID), digest)
+			pipe.PExpire(ctx, familyKey(accessToken.FamilyID), c.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// invalidateID drops the entry of a single token.
+// A lookup racing with the revocation can still refill it, the ttl bounds such entries.
+func (c *TokenCache) invalidateID(ctx context.Context, id int) {
+	const op = "repositories.TokenCache.invalidateID"
+
+	digest, err := c.redis.GetDel(ctx, idKey(id)).Result()
+	if err == redis.Nil {
+		return
+	}
+	if err == nil {
+		err = c.redis.Del(ctx, entryKey(digest)).Err()
+	}
+	if err != nil {
+		tokenCacheMetrics.Add("errors", 1)
+		c.log.Warn("token cache invalidation failed", slog.String("op", op), slog.String("error", err.Error()))
+		return
+	}
+
+	tokenCacheMetrics.Add("invalidations", 1)
+}
+
+// invalidateSet drops the entries of every token in a user or family set
+func (c *TokenCache) invalidateSet(ctx context.Context, setKey string) {
+	const op = "repositories.TokenCache.invalidateSet"
+
+	digests, err := c.redis.SMembers(ctx, setKey).Result()
+	if err == nil {
+		keys := make([]string, 0, len(digests)+1)
+		keys = append(keys, setKey)
+		for _, digest := range digests {
+			keys = append(keys, entryKey(digest))
+		}
+		err = c.redis.Del(ctx, keys...).Err()
+	}
+	if err != nil {
+		tokenCacheMetrics.Add("errors", 1)
+		c.log.Warn("token cache invalidation failed", slog.String("op", op), slog.String("error", err.Error()))
+		return
+	}
+
+	tokenCacheMetrics.Add("invalidations", int64(len(digests)))
+}
+
+func parseCachedToken(fields map[string]string) (*entity.AccessToken, error) {
+	var ints [5]int64
+	for i, name := range []string{"id", "user_id", "last_used_at", "created_at", "updated_at"} {
+		v, err := strconv.ParseInt(fields[name], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = v
+	}
+
+	totpPending, err := strconv.ParseBool(fields["totp_pending"])
+	if err != nil {
+		return nil, err
+	}
+
+	if fields["role"] == "" || fields["status"] == "" {
+		return nil, errors.New("owner of the token is missing")
+	}
+
+	return &entity.AccessToken{
+		ID:          int(ints[0]),
+		UserID:      int(ints[1]),
+		FamilyID:    fields["family_id"],
+		UserAgent:   fields["user_agent"],
+		IP:          fields["ip"],
+		LastUsedAt:  time.Unix(0, ints[2]),
+		CreatedAt:   time.Unix(0, ints[3]),
+		UpdatedAt:   time.Unix(0, ints[4]),
+		TOTPPending: totpPending,
+		Role:        fields["role"],
+		Status:      fields["status"],
+	}, nil
+}
+
+func entryKey(digest string) string {
+	return _tokenCachePrefix + digest
+}
+
+func idKey(id int) string {
+	return _tokenCachePrefix + "id:" + strconv.Itoa(id)
+}
+
+func userKey(userID int) string {
+	return _tokenCachePrefix + "user:" + strconv.Itoa(userID)
+}
+
+func familyKey(familyID string) string {
+	return _tokenCachePrefix + "family:" + familyID
+}
@@ -0,0 +1,140 @@
+package identity
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys set by the gateway for the caller of a request.
+// The signature is a hex HMAC-SHA256 of "user_id\nrole\ntimestamp" with the shared secret.
+const (
+	MDUserID    = "x-user-id"
+	MDUserRole  = "x-user-role"
+	MDTimestamp = "x-user-ts"
+	MDSignature = "x-user-signature"
+)
+
+var (
+	ErrNoSecret         = errors.New("identity secret is not configured")
+	ErrMalformed        = errors.New("malformed identity metadata")
+	ErrTimestampSkew    = errors.New("identity timestamp is out of the allowed skew")
+	ErrInvalidSignature = errors.New("invalid identity signature")
+)
+
+// Identity is the caller of a request authenticated by the gateway
+type Identity struct {
+	UserID int64
+	Role   string
+}
+
+type ctxKey struct{}
+
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(ctxKey{}).(Identity)
+	return id, ok
+}
+
+// Verifier checks caller identities signed by the gateway
+type Verifier struct {
+	secret  []byte
+	maxSkew time.Duration
+	now     func() time.Time
+}
+
+// NewVerifier returns a verifier accepting signatures made at most maxSkew from now.
+// Without a secret every call carrying an identity is rejected.
+func NewVerifier(secret string, maxSkew time.Duration) *Verifier {
+	return &Verifier{
+		secret:  []byte(secret),
+		maxSkew: maxSkew,
+		now:     time.Now,
+	}
+}
+
+// Verify checks the identity metadata of an incoming call, ok is false when the call carries none
+func (v *Verifier) Verify(md metadata.MD) (id Identity, ok bool, err error) {
+	keys := []string{MDUserID, MDUserRole, MDTimestamp, MDSignature}
+
+	var values [4]string
+	present := false
+	for i, key := range keys {
+		vals := md.Get(key)
+		if len(vals) > 1 {
+			return Identity{}, false, ErrMalformed
+		}
+		if len(vals) == 1 {
+			values[i] = vals[0]
+			present = true
+		}
+	}
+	if !present {
+		return Identity{}, false, nil
+	}
+
+	if len(v.secret) == 0 {
+		return Identity{}, false, ErrNoSecret
+	}
+
+	userID, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return Identity{}, false, ErrMalformed
+	}
+	ts, err := strconv.ParseInt(values[2], 10, 64)
+	if err != nil {
+		return Identity{}, false, ErrMalformed
+	}
+	signature, err := hex.DecodeString(values[3])
+	if err != nil {
+		return Identity{}, false, ErrMalformed
+	}
+
+	skew := v.now().Sub(time.Unix(ts, 0))
+	if skew > v.maxSkew || skew < -v.maxSkew {
+		return Identity{}, false, ErrTimestampSkew
+	}
+
+	id = Identity{UserID: userID, Role: values[1]}
+	if !hmac.Equal(signature, v.sign(id, ts)) {
+		return Identity{}, false, ErrInvalidSignature
+	}
+
+	return id, true, nil
+}
+
+func (v *Verifier) sign(id Identity, timestamp int64) []byte {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(strconv.FormatInt(id.UserID, 10) + "\n" + id.Role + "\n" + strconv.FormatInt(timestamp, 10)))
+	return mac.Sum(nil)
+}
+
+// UnaryServerInterceptor rejects calls carrying an identity that fails verification
+// and stores a verified one in the context. Calls without an identity pass unchanged.
+func (v *Verifier) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		id, ok, err := v.Verify(md)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid caller identity")
+		}
+		if ok {
+			ctx = WithIdentity(ctx, id)
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,104 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The gateway identity tests sign the same vector, a change of the signed format on
+// either side breaks one of them
+const (
+	_testSecret    = "test-identity-secret"
+	_testTimestamp = 1700000000
+	_testSignature = "147c7532a86153e50b2232dc1cdc29d9e82c4f45ae81950d5d781095e3609d80"
+)
+
+func testVerifier(secret string) *Verifier {
+	v := NewVerifier(secret, 30*time.Second)
+	v.now = func() time.Time { return time.Unix(_testTimestamp+10, 0) }
+	return v
+}
+
+func signedMD(role, ts, signature string) metadata.MD {
+	return metadata.Pairs(
+		MDUserID, "42",
+		MDUserRole, role,
+		MDTimestamp, ts,
+		MDSignature, signature,
+	)
+}
+
+func TestUnaryServerInterceptorAcceptsGatewaySignature(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), signedMD("admin", "1700000000", _testSignature))
+
+	var got Identity
+	var ok bool
+	_, err := testVerifier(_testSecret).UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req any) (any, error) {
+			got, ok = FromContext(ctx)
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Identity{UserID: 42, Role: "admin"}
+	if !ok || got != want {
+		t.Fatalf("identity = %+v, %v; want %+v", got, ok, want)
+	}
+}
+
+func TestUnaryServerInterceptorRejectsInvalidIdentity(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		md     metadata.MD
+	}{
+		{"tampered role", _testSecret, signedMD("superadmin", "1700000000", _testSignature)},
+		{"other secret", "other-secret", signedMD("admin", "1700000000", _testSignature)},
+		{"no secret", "", signedMD("admin", "1700000000", _testSignature)},
+		{"stale timestamp", _testSecret, signedMD("admin", "1699999000", _testSignature)},
+		{"malformed signature", _testSecret, signedMD("admin", "1700000000", "not-hex")},
+		{"missing signature", _testSecret, metadata.Pairs(MDUserID, "42", MDUserRole, "admin")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			called := false
+			_, err := testVerifier(tt.secret).UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{},
+				func(ctx context.Context, req any) (any, error) {
+					called = true
+					return nil, nil
+				})
+			if status.Code(err) != codes.Unauthenticated {
+				t.Fatalf("code = %v, want %v", status.Code(err), codes.Unauthenticated)
+			}
+			if called {
+				t.Fatal("handler was called")
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutIdentity(t *testing.T) {
+	_, ok, err := testVerifier("").Verify(metadata.MD{})
+	if ok || err != nil {
+		t.Fatalf("Verify = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestVerifyStaleTimestamp(t *testing.T) {
+	_, _, err := testVerifier(_testSecret).Verify(signedMD("admin", "1699999000", _testSignature))
+	if !errors.Is(err, ErrTimestampSkew) {
+		t.Fatalf("err = %v, want %v", err, ErrTimestampSkew)
+	}
+}
@@ -0,0 +1,318 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/config"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+)
+
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+
+	_rsaKeyBits = 2048
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidIssuer    = errors.New("invalid issuer")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrUnsupportedKey   = errors.New("unsupported key")
+)
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+type key struct {
+	id     string
+	alg    string
+	signer crypto.Signer
+}
+
+// KeySet signs and verifies access tokens.
+// Every loaded key is published and accepted, only the signing key issues new tokens.
+type KeySet struct {
+	issuer  string
+	signing *key
+	keys    map[string]*key
+	ids     []string
+}
+
+// NewKeySet loads PEM encoded private keys from cfg.KeysPath, the file name without
+// extension is used as the key ID. Without a keys path an ephemeral key is generated.
+func NewKeySet(log *slog.Logger, cfg config.JWTConfig) (*KeySet, error) {
+	const op = "jwt.NewKeySet"
+
+	ks := &KeySet{
+		issuer: cfg.Issuer,
+		keys:   make(map[string]*key),
+	}
+
+	if cfg.KeysPath == "" {
+		log.Warn("jwt keys path is empty, generating ephemeral signing key", slog.String("op", op))
+
+		k, err := generateKey(cfg.Algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ks.add(k)
+		ks.signing = k
+
+		return ks, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(cfg.KeysPath, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, file := range files {
+		k, err := loadKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, file, err)
+		}
+		ks.add(k)
+	}
+
+	if len(ks.ids) == 0 {
+		return nil, fmt.Errorf("%s: no keys found in %s", op, cfg.KeysPath)
+	}
+
+	// Default to the last key in lexical order, so dated file names rotate naturally
+	sort.Strings(ks.ids)
+	signingID := cfg.SigningKeyID
+	if signingID == "" {
+		signingID = ks.ids[len(ks.ids)-1]
+	}
+
+	signing, ok := ks.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("%s: signing key %q not found", op, signingID)
+	}
+	ks.signing = signing
+
+	log.Info("jwt keys loaded",
+		slog.String("op", op),
+		slog.Any("kids", ks.ids),
+		slog.String("signing_kid", signingID),
+	)
+
+	return ks, nil
+}
+
+func (ks *KeySet) add(k *key) {
+	ks.keys[k.id] = k
+	ks.ids = append(ks.ids, k.id)
+}
+
+// Sign issues a signed token with the current signing key
+func (ks *KeySet) Sign(claims entity.TokenClaims) (string, error) {
+	const op = "jwt.KeySet.Sign"
+
+	claims.Issuer = ks.issuer
+
+	h, err := json.Marshal(header{Alg: ks.signing.alg, Typ: "JWT", Kid: ks.signing.id})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	p, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	signingInput := encode(h) + "." + encode(p)
+
+	sig, err := sign(ks.signing, []byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return signingInput + "." + encode(sig), nil
+}
+
+// Verify checks the token signature, issuer and expiry and returns its claims
+func (ks *KeySet) Verify(token string) (*entity.TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	k, ok := ks.keys[h.Kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if h.Alg != k.alg {
+		return nil, ErrInvalidSignature
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if !verify(k, []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrInvalidSignature
+	}
+
+	var claims entity.TokenClaims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if claims.Issuer != ks.issuer {
+		return nil, ErrInvalidIssuer
+	}
+	if claims.ExpiresAt != 0 && time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+
+	return &claims, nil
+}
+
+// PublicKeys returns every active key in JWK format
+func (ks *KeySet) PublicKeys() []entity.JWK {
+	jwks := make([]entity.JWK, 0, len(ks.ids))
+	for _, id := range ks.ids {
+		k := ks.keys[id]
+		jwk := entity.JWK{
+			Kid: k.id,
+			Alg: k.alg,
+			Use: "sig",
+		}
+
+		switch pub := k.signer.Public().(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = encode(pub)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encode(pub.N.Bytes())
+			jwk.E = encode(big.NewInt(int64(pub.E)).Bytes())
+		}
+
+		jwks = append(jwks, jwk)
+	}
+
+	return jwks
+}
+
+func sign(k *key, data []byte) ([]byte, error) {
+	switch k.alg {
+	case AlgEdDSA:
+		return k.signer.Sign(rand.Reader, data, crypto.Hash(0))
+	case AlgRS256:
+		digest := sha256.Sum256(data)
+		return k.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+func verify(k *key, data, sig []byte) bool {
+	switch pub := k.signer.Public().(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, data, sig)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return false
+	}
+}
+
+func loadKey(file string) (*key, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+
+	switch pk := parsed.(type) {
+	case ed25519.PrivateKey:
+		return &key{id: id, alg: AlgEdDSA, signer: pk}, nil
+	case *rsa.PrivateKey:
+		return &key{id: id, alg: AlgRS256, signer: pk}, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+func generateKey(alg string) (*key, error) {
+	id := fmt.Sprintf("ephemeral-%d", time.Now().Unix())
+
+	switch alg {
+	case AlgEdDSA:
+		_, pk, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return &key{id: id, alg: AlgEdDSA, signer: pk}, nil
+	case AlgRS256:
+		pk, err := rsa.GenerateKey(rand.Reader, _rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		return &key{id: id, alg: AlgRS256, signer: pk}, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJSON(s string, dest any) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dest)
+}
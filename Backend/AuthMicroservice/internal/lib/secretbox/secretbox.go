@@ -0,0 +1,68 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+const KeySize = 32
+
+var (
+	ErrInvalidKey        = errors.New("key must be 32 bytes")
+	ErrMalformedSealed   = errors.New("malformed sealed data")
+	ErrDecryptionFailure = errors.New("decryption failed")
+)
+
+// Box encrypts small secrets at rest with AES-256-GCM.
+// The random nonce is prepended to the ciphertext.
+type Box struct {
+	aead cipher.AEAD
+}
+
+func New(key []byte) (*Box, error) {
+	const op = "secretbox.New"
+
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidKey)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Box{aead: aead}, nil
+}
+
+// Encrypt seals the plaintext
+func (b *Box) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return b.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt opens data sealed by Encrypt
+func (b *Box) Decrypt(sealed []byte) ([]byte, error) {
+	size := b.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, ErrMalformedSealed
+	}
+
+	plaintext, err := b.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, ErrDecryptionFailure
+	}
+
+	return plaintext, nil
+}
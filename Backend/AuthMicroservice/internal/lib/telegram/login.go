@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+)
+
+var (
+	ErrInvalidHash = errors.New("invalid telegram login hash")
+	ErrAuthExpired = errors.New("telegram login is too old")
+)
+
+// VerifyLogin checks a Telegram Login Widget payload signed with the bot token
+// and rejects payloads issued more than maxAge ago
+func VerifyLogin(botToken string, auth entity.TelegramAuth, maxAge time.Duration, now time.Time) error {
+	expected, err := hex.DecodeString(auth.Hash)
+	if err != nil {
+		return ErrInvalidHash
+	}
+
+	// The secret key is the SHA-256 of the bot token, not the token itself
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(dataCheckString(auth)))
+
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidHash
+	}
+
+	issuedAt := time.Unix(auth.AuthDate, 0)
+	if now.Sub(issuedAt) > maxAge || issuedAt.After(now.Add(time.Minute)) {
+		return ErrAuthExpired
+	}
+
+	return nil
+}
+
+// dataCheckString joins the received fields except hash as sorted key=value lines
+func dataCheckString(auth entity.TelegramAuth) string {
+	fields := map[string]string{
+		"id":         strconv.FormatInt(auth.ID, 10),
+		"first_name": auth.FirstName,
+		"last_name":  auth.LastName,
+		"username":   auth.Username,
+		"photo_url":  auth.PhotoURL,
+		"auth_date":  strconv.FormatInt(auth.AuthDate, 10),
+	}
+
+	lines := make([]string, 0, len(fields))
+	for k, v := range fields {
+		// Telegram omits empty fields from the payload
+		if v == "" {
+			continue
+		}
+		lines = append(lines, k+"="+v)
+	}
+	sort.Strings(lines)
+
+	return strings.Join(lines, "\n")
+}
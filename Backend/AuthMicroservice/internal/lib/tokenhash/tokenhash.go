@@ -0,0 +1,13 @@
+package tokenhash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Sum returns the hex encoded SHA-256 digest of a token.
+// Tokens are random and long, so an unsalted digest cannot be reversed.
+func Sum(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
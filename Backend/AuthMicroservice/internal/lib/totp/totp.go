@@ -0,0 +1,94 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	Digits = 6
+	Period = 30 * time.Second
+
+	_secretSize = 20
+	// _skew is the number of periods accepted before and after the current one
+	_skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random shared secret
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, _secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// Encode returns the secret in the base32 form used by authenticator apps
+func Encode(secret []byte) string {
+	return encoding.EncodeToString(secret)
+}
+
+// URI returns the otpauth URI of the secret, usually rendered as a QR code
+func URI(issuer, account string, secret []byte) string {
+	label := url.PathEscape(issuer + ":" + account)
+
+	params := url.Values{}
+	params.Set("secret", Encode(secret))
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(Digits))
+	params.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Code returns the code of the period containing t
+func Code(secret []byte, t time.Time) string {
+	return code(secret, step(t))
+}
+
+// Validate checks the code against the periods around t.
+// The matched period is returned so callers can reject a replayed code.
+func Validate(secret []byte, passcode string, t time.Time) (int64, bool) {
+	if len(passcode) != Digits {
+		return 0, false
+	}
+
+	current := step(t)
+	for i := -_skew; i <= _skew; i++ {
+		s := current + int64(i)
+		if subtle.ConstantTimeCompare([]byte(code(secret, s)), []byte(passcode)) == 1 {
+			return s, true
+		}
+	}
+
+	return 0, false
+}
+
+func step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// code implements the HOTP truncation of RFC 4226
+func code(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", Digits, value%1000000)
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"log/slog"
+	"strconv"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+)
+
+const (
+	_defaultUsersPageSize = 50
+	_maxUsersPageSize     = 100
+)
+
+// ListUsers returns a page of users matching the filter.
+// The cursor is the opaque NextCursor of the previous page.
+func (s *AuthService) ListUsers(ctx context.Context, filter entity.UserFilter, cursor string) (*entity.UserPage, error) {
+	const op = "AuthService.ListUsers"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if cursor != "" {
+		afterID, err := decodeCursor(cursor)
+		if err != nil {
+			log.Info("invalid cursor", slog.String("error", err.Error()))
+			return nil, ErrInvalidCursor
+		}
+		filter.AfterID = afterID
+	}
+
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = _defaultUsersPageSize
+	case filter.Limit > _maxUsersPageSize:
+		filter.Limit = _maxUsersPageSize
+	}
+
+	// Fetch one extra row to know whether another page exists
+	limit := filter.Limit
+	filter.Limit++
+
+	users, err := s.userRepo.List(ctx, filter)
+	if err != nil {
+		log.Error("failed to list users", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	page := &entity.UserPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		page.NextCursor = encodeCursor(page.Users[limit-1].ID)
+	}
+
+	return page, nil
+}
+
+// GetUser returns a user by ID
+func (s *AuthService) GetUser(ctx context.Context, userID int) (*entity.User, error) {
+	const op = "AuthService.GetUser"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("user_id", userID),
+	)
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, ErrAccountNotFound
+	}
+
+	return user, nil
+}
+
+// DeleteUser marks a user as deleted and revokes all of their tokens.
+// The username stays reserved.
+func (s *AuthService) DeleteUser(ctx context.Context, userID int) error {
+	const op = "AuthService.DeleteUser"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("user_id", userID),
+	)
+
+	log.Info("deleting user")
+
+	err := s.setStatus(ctx, userID, entity.StatusDeleted)
+	if err != nil {
+		log.Error("failed to delete user", slog.String("error", err.Error()))
+		return err
+	}
+
+	err = s.tokenRepo.DeleteUserTokens(ctx, userID)
+	if err != nil {
+		log.Error("failed to revoke sessions", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("user deleted")
+	return nil
+}
+
+// BlockUser blocks a user and revokes all of their sessions
+func (s *AuthService) BlockUser(ctx context.Context, userID int) error {
+	const op = "AuthService.BlockUser"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("user_id", userID),
+	)
+
+	log.Info("blocking user")
+
+	err := s.setStatus(ctx, userID, entity.StatusBlocked)
+	if err != nil {
+		log.Error("failed to block user", slog.String("error", err.Error()))
+		return err
+	}
+
+	err = s.tokenRepo.DeleteUserTokens(ctx, userID)
+	if err != nil {
+		log.Error("failed to revoke sessions", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("user blocked")
+	return nil
+}
+
+// UnblockUser lifts a block of a user, accounts that are not blocked are left as is
+func (s *AuthService) UnblockUser(ctx context.Context, userID int) error {
+	const op = "AuthService.UnblockUser"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("user_id", userID),
+	)
+
+	log.Info("unblocking user")
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return ErrAccountNotFound
+	}
+
+	if user.Status != entity.StatusBlocked {
+		log.Info("user is not blocked", slog.String("status", user.Status))
+		return nil
+	}
+
+	err = s.userRepo.SetStatus(ctx, userID, entity.StatusActive)
+	if err != nil {
+		log.Error("failed to unblock user", slog.String("error", err.Error()))
+		return err
+	}
+	s.invalidateSessions(ctx, userID)
+
+	log.Info("user unblocked")
+	return nil
+}
+
+func (s *AuthService) setStatus(ctx context.Context, userID int, status string) error {
+	_, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+
+	err = s.userRepo.SetStatus(ctx, userID, status)
+	if err != nil {
+		return err
+	}
+
+	s.invalidateSessions(ctx, userID)
+	return nil
+}
+
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(b))
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// maxLockoutShift bounds the exponent of the lockout back-off
+const maxLockoutShift = 20
+
+// LockedError reports a temporary lockout after too many failed attempts
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// checkLockout returns a LockedError when any of the subjects is locked out
+func (s *AuthService) checkLockout(ctx context.Context, subjects ...string) error {
+	if s.attemptLimit.MaxAttempts <= 0 {
+		return nil
+	}
+
+	var retryAfter time.Duration
+	for _, subject := range subjects {
+		ttl, err := s.authCodes.TTL(ctx, lockoutKey(subject))
+		if err != nil {
+			return err
+		}
+		retryAfter = max(retryAfter, ttl)
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// registerFailure counts a failed attempt for every subject and locks out the
+// subjects that exceeded the limit
+func (s *AuthService) registerFailure(ctx context.Context, log *slog.Logger, subjects ...string) {
+	if s.attemptLimit.MaxAttempts <= 0 {
+		return
+	}
+
+	for _, subject := range subjects {
+		key := attemptsKey(subject)
+
+		attempts, err := s.authCodes.Incr(ctx, key, s.attemptLimit.Window)
+		if err != nil {
+			log.Error("failed to count attempt", slog.String("error", err.Error()))
+			continue
+		}
+
+		if attempts < int64(s.attemptLimit.MaxAttempts) {
+			continue
+		}
+
+		lockout := s.lockoutDuration(attempts)
+		err = s.authCodes.Set(ctx, lockoutKey(subject), attempts, lockout)
+		if err != nil {
+			log.Error("failed to set lockout", slog.String("error", err.Error()))
+			continue
+		}
+
+		// Keep counting while locked out so the back-off keeps growing
+		err = s.authCodes.Expire(ctx, key, lockout+s.attemptLimit.Window)
+		if err != nil {
+			log.Error("failed to extend attempts window", slog.String("error", err.Error()))
+		}
+
+		log.Warn("too many failed attempts, locked out",
+			slog.String("subject", subject),
+			slog.Int64("attempts", attempts),
+			slog.Duration("lockout", lockout),
+		)
+	}
+}
+
+// resetAttempts forgets failed attempts of the subjects after a success
+func (s *AuthService) resetAttempts(ctx context.Context, log *slog.Logger, subjects ...string) {
+	if s.attemptLimit.MaxAttempts <= 0 {
+		return
+	}
+
+	for _, subject := range subjects {
+		_, err := s.authCodes.Del(ctx, attemptsKey(subject))
+		if err != nil && !errors.Is(err, ErrCacheNotFound) {
+			log.Error("failed to reset attempts", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// lockoutDuration doubles the base lockout for every attempt above the limit, up to maxLockoutShift
+// doublings. Without a max lockout the back-off is only bounded by the shift.
+func (s *AuthService) lockoutDuration(attempts int64) time.Duration {
+	shift := min(max(attempts-int64(s.attemptLimit.MaxAttempts), 0), maxLockoutShift)
+
+	lockout := s.attemptLimit.BaseLockout << shift
+	// A base lockout too long to be doubled that often saturates instead of wrapping around
+	if s.attemptLimit.BaseLockout > math.MaxInt64>>shift {
+		lockout = math.MaxInt64
+	}
+
+	if s.attemptLimit.MaxLockout > 0 && lockout > s.attemptLimit.MaxLockout {
+		return s.attemptLimit.MaxLockout
+	}
+	return lockout
+}
+
+func attemptsKey(subject string) string {
+	return "attempts:" + subject
+}
+
+func lockoutKey(subject string) string {
+	return "lockout:" + subject
+}
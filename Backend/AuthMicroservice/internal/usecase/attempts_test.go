@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    entity.AttemptLimit
+		attempts int64
+		want     time.Duration
+	}{
+		{
+			name:     "at the limit",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: time.Minute, MaxLockout: time.Hour},
+			attempts: 5,
+			want:     time.Minute,
+		},
+		{
+			name:     "doubles per attempt",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: time.Minute, MaxLockout: time.Hour},
+			attempts: 8,
+			want:     8 * time.Minute,
+		},
+		{
+			name:     "capped",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: time.Minute, MaxLockout: time.Hour},
+			attempts: 12,
+			want:     time.Hour,
+		},
+		{
+			name:     "capped past the max shift",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: time.Minute, MaxLockout: time.Hour},
+			attempts: 5 + maxLockoutShift + 1,
+			want:     time.Hour,
+		},
+		{
+			name:     "uncapped",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: time.Minute},
+			attempts: 10,
+			want:     32 * time.Minute,
+		},
+		{
+			name:     "uncapped at the max shift",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: time.Minute},
+			attempts: 5 + maxLockoutShift,
+			want:     time.Minute << maxLockoutShift,
+		},
+		{
+			name:     "uncapped past the max shift",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: time.Minute},
+			attempts: math.MaxInt64,
+			want:     time.Minute << maxLockoutShift,
+		},
+		{
+			name:     "long base saturates",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: 24 * time.Hour},
+			attempts: 5 + maxLockoutShift,
+			want:     math.MaxInt64,
+		},
+		{
+			name:     "long base capped",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: 24 * time.Hour, MaxLockout: 48 * time.Hour},
+			attempts: 5 + maxLockoutShift,
+			want:     48 * time.Hour,
+		},
+		{
+			name:     "below the limit",
+			limit:    entity.AttemptLimit{MaxAttempts: 5, BaseLockout: time.Minute, MaxLockout: time.Hour},
+			attempts: 3,
+			want:     time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AuthService{attemptLimit: tt.limit}
+			if got := s.lockoutDuration(tt.attempts); got != tt.want {
+				t.Fatalf("lockoutDuration(%d) = %v, want %v", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
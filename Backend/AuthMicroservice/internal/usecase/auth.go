@@ -3,36 +3,73 @@ package usecase
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
 	"errors"
 	"log/slog"
 	"math/big"
+	"strconv"
 	"time"
 
 	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/jwt"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// _authCodeRetries limits attempts to find an auth code not used by another user
+const _authCodeRetries = 3
+
 var (
 	ErrAccountAlreadyExists = errors.New("account with this credentials already exists")
 	ErrAccountNotFound      = errors.New("account with this credentials not found")
 	ErrBadCredentials       = errors.New("bad credentials")
 	ErrTokenNotFound        = errors.New("token not found")
+	ErrTokenExpired         = errors.New("token expired")
+	ErrTokenReused          = errors.New("token reuse detected")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrInvalidResetCode     = errors.New("invalid or expired reset code")
+	ErrTooManyAttempts      = errors.New("too many attempts")
+	ErrRoleAlreadyExists    = errors.New("role already exists")
+	ErrRoleInUse            = errors.New("role is assigned to users")
+	ErrPermissionExists     = errors.New("permission already exists")
+	ErrPermissionNotFound   = errors.New("permission not found")
+	ErrInvalidCursor        = errors.New("invalid cursor")
 	ErrLinkNotFound         = errors.New("link not found")
 	ErrNotActivated         = errors.New("not activated account")
+	ErrAccountBlocked       = errors.New("account is blocked")
 	ErrInvalidRole          = errors.New("invalid role")
 	ErrVerificationFailed   = errors.New("verification failed")
 	ErrTgConnNotFound       = errors.New("telegram connectino not found")
 	ErrCacheNotFound        = errors.New("cache not found")
+	ErrTOTPNotEnrolled      = errors.New("two-factor authentication is not enrolled")
+	ErrTOTPAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTOTPRequired         = errors.New("two-factor authentication is required for this role")
+	ErrTOTPPending          = errors.New("two-factor authentication must be enabled first")
+	ErrInvalidTOTPCode      = errors.New("invalid two-factor code")
+	ErrChallengeNotFound    = errors.New("login challenge not found")
+	ErrInvalidTelegramAuth  = errors.New("invalid telegram login")
+	ErrTelegramLoginOff     = errors.New("telegram login is not configured")
+	ErrTelegramLinked       = errors.New("telegram account is linked to another user")
+	ErrInvalidServiceToken  = errors.New("invalid service token")
+	ErrServiceTokenNotFound = errors.New("service token not found")
+	ErrServiceTokenExists   = errors.New("service already has a valid token")
+	ErrInvalidScope         = errors.New("unknown service token scope")
+	ErrScopeNotGranted      = errors.New("service token scope is not granted")
+	ErrAuthCodeCollision    = errors.New("failed to generate a unique auth code")
+	ErrProfileNotFound      = errors.New("profile not found")
+	ErrInvalidProfile       = errors.New("invalid profile")
+	ErrReservedUsername     = errors.New("username is reserved")
 )
 
 type UserRepoI interface {
 	GetByID(ctx context.Context, id int) (*entity.User, error)
 	GetByUsername(ctx context.Context, username string) (*entity.User, error)
-	Create(ctx context.Context, username, password string) (*entity.User, error)
+	Create(ctx context.Context, username, password, status string) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id int) error
 	UpdateUserRole(ctx context.Context, userID, roleID int) error
+	List(ctx context.Context, filter entity.UserFilter) ([]entity.User, error)
+	SetStatus(ctx context.Context, id int, status string) error
 }
 
 type RoleRepoI interface {
@@ -41,20 +78,65 @@ type RoleRepoI interface {
 	Create(ctx context.Context, title string) (*entity.Role, error)
 	Update(ctx context.Context, role *entity.Role) error
 	Delete(ctx context.Context, id int) error
+	GetAll(ctx context.Context) ([]entity.Role, error)
+	CountUsers(ctx context.Context, roleID int) (int, error)
+	CreatePermission(ctx context.Context, title, description string) (*entity.Permission, error)
+	GetPermissionByTitle(ctx context.Context, title string) (*entity.Permission, error)
+	GetAllPermissions(ctx context.Context) ([]entity.Permission, error)
+	DeletePermission(ctx context.Context, id int) error
+	GetPermissionsByRoleID(ctx context.Context, roleID int) ([]string, error)
+	AddPermission(ctx context.Context, roleID, permissionID int) error
+	RemovePermission(ctx context.Context, roleID, permissionID int) error
+	HasPermission(ctx context.Context, userID int, permission string) (bool, error)
+}
+
+// SessionCacheI is implemented by token repositories that cache the owner of a token,
+// their entries of a user are dropped when the role or status of the user changes
+type SessionCacheI interface {
+	InvalidateUser(ctx context.Context, userID int)
 }
 
 type TokenRepoI interface {
-	CreateServiceToken(ctx context.Context, serviceName, token string) (*entity.SerivceToken, error)
+	CreateServiceToken(ctx context.Context, serviceName, token string, scopes []string, expiresAt *time.Time) (*entity.SerivceToken, error)
 	GetServiceTokenByServiceName(ctx context.Context, serviceName string) (*entity.SerivceToken, error)
 	GetServiceTokenByToken(ctx context.Context, token string) (*entity.SerivceToken, error)
-	CreateAccessToken(ctx context.Context, userID int, token string) (*entity.AccessToken, error)
+	UpdateServiceToken(ctx context.Context, serviceToken *entity.SerivceToken) error
+	DeleteServiceToken(ctx context.Context, id int) error
+	GetAllServiceTokens(ctx context.Context) ([]entity.SerivceToken, error)
+	CreateAccessToken(ctx context.Context, userID int, token, familyID string, client entity.ClientInfo, totpPending bool) (*entity.AccessToken, error)
 	GetAccessTokenByToken(ctx context.Context, token string) (*entity.AccessToken, error)
+	GetAccessTokensByUserID(ctx context.Context, userID int) ([]entity.AccessToken, error)
+	RenewAccessToken(ctx context.Context, id int) error
 	DeleteAccessToken(ctx context.Context, id int) error
+	DeleteAccessTokensByFamily(ctx context.Context, familyID string) error
+	DeleteUserTokens(ctx context.Context, userID int) error
+	DeleteExpiredAccessTokens(ctx context.Context, createdBefore, usedBefore time.Time) (int64, error)
+	CreateRefreshToken(ctx context.Context, userID int, familyID, token string, expiresAt time.Time) (*entity.RefreshToken, error)
+	GetRefreshTokenByToken(ctx context.Context, token string) (*entity.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, id int) (bool, error)
+	DeleteTokenFamily(ctx context.Context, familyID string) error
+	DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error)
 }
 
 type TgConnectionRepoI interface {
 	Create(ctx context.Context, userID int, tgUserID int) (*entity.TgConnection, error)
 	GetByUserID(ctx context.Context, userID int) (*entity.TgConnection, error)
+	GetByTgUserID(ctx context.Context, tgUserID int) (*entity.TgConnection, error)
+	Update(ctx context.Context, tgConnection *entity.TgConnection) error
+	DeleteByUserID(ctx context.Context, userID int) error
+}
+
+type TOTPRepoI interface {
+	Upsert(ctx context.Context, userID int, secret []byte, recoveryCodes []string) error
+	GetByUserID(ctx context.Context, userID int) (*entity.TOTP, error)
+	Confirm(ctx context.Context, userID int) error
+	Delete(ctx context.Context, userID int) error
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+}
+
+type ProfileRepoI interface {
+	GetByUserID(ctx context.Context, userID int) (*entity.Profile, error)
+	Upsert(ctx context.Context, profile *entity.Profile) error
 }
 
 type RedisRepository interface {
@@ -62,6 +144,28 @@ type RedisRepository interface {
 	Del(ctx context.Context, key string) (res int64, err error)
 	Get(ctx context.Context, key string, dest any) error
 	Expire(ctx context.Context, key string, expiration time.Duration) error
+	Incr(ctx context.Context, key string, expiration time.Duration) (int64, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	SetHashNX(ctx context.Context, key string, fields map[string]any, expiration time.Duration) (bool, error)
+	ClaimAttempt(ctx context.Context, key, counter string) (map[string]string, error)
+}
+
+// Notifier delivers messages to users out of band
+type Notifier interface {
+	Notify(ctx context.Context, userID int, message string) error
+}
+
+// SecretBox encrypts secrets stored at rest
+type SecretBox interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(sealed []byte) ([]byte, error)
+}
+
+// TokenSigner issues and verifies self-contained access tokens
+type TokenSigner interface {
+	Sign(claims entity.TokenClaims) (string, error)
+	Verify(token string) (*entity.TokenClaims, error)
+	PublicKeys() []entity.JWK
 }
 
 type AuthService struct {
@@ -72,6 +176,24 @@ type AuthService struct {
 	tgConn    TgConnectionRepoI
 	authCodes RedisRepository
 	authCode  entity.AuthCode
+	tokenTTL  entity.TokenTTL
+	signer    TokenSigner
+	resetCode entity.AuthCode
+	notifier  Notifier
+
+	attemptLimit entity.AttemptLimit
+	activation   entity.Activation
+
+	totpRepo   TOTPRepoI
+	secretBox  SecretBox
+	totpPolicy entity.TOTPPolicy
+
+	telegramLogin entity.TelegramLogin
+
+	serviceTokens entity.ServiceTokenPolicy
+
+	profileRepo   ProfileRepoI
+	profilePolicy entity.ProfilePolicy
 }
 
 func NewAuthService(
@@ -82,6 +204,19 @@ func NewAuthService(
 	tgConn TgConnectionRepoI,
 	authCodes RedisRepository,
 	authCode entity.AuthCode,
+	tokenTTL entity.TokenTTL,
+	signer TokenSigner,
+	resetCode entity.AuthCode,
+	notifier Notifier,
+	attemptLimit entity.AttemptLimit,
+	activation entity.Activation,
+	totpRepo TOTPRepoI,
+	secretBox SecretBox,
+	totpPolicy entity.TOTPPolicy,
+	telegramLogin entity.TelegramLogin,
+	serviceTokens entity.ServiceTokenPolicy,
+	profileRepo ProfileRepoI,
+	profilePolicy entity.ProfilePolicy,
 ) *AuthService {
 	return &AuthService{
 		log:       log,
@@ -91,11 +226,29 @@ func NewAuthService(
 		tgConn:    tgConn,
 		authCodes: authCodes,
 		authCode:  authCode,
+		tokenTTL:  tokenTTL,
+		signer:    signer,
+		resetCode: resetCode,
+		notifier:  notifier,
+
+		attemptLimit: attemptLimit,
+		activation:   activation,
+
+		totpRepo:   totpRepo,
+		secretBox:  secretBox,
+		totpPolicy: totpPolicy,
+
+		telegramLogin: telegramLogin,
+
+		serviceTokens: serviceTokens,
+
+		profileRepo:   profileRepo,
+		profilePolicy: profilePolicy,
 	}
 }
 
 // Login handles user login
-func (s *AuthService) Login(ctx context.Context, username, password string) (*entity.LoginResponse, error) {
+func (s *AuthService) Login(ctx context.Context, username, password string, client entity.ClientInfo) (*entity.LoginResponse, error) {
 	const op = "AuthService.Login"
 
 	log := s.log.With(
@@ -105,10 +258,23 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (*en
 
 	log.Info("login attempt")
 
+	// Refuse attempts while the username or the client is locked out
+	subjects := []string{"login:user:" + username}
+	if client.IP != "" {
+		subjects = append(subjects, "login:ip:"+client.IP)
+	}
+
+	err := s.checkLockout(ctx, subjects...)
+	if err != nil {
+		log.Warn("login locked out", slog.String("error", err.Error()))
+		return nil, err
+	}
+
 	// Get user by username
 	user, err := s.userRepo.GetByUsername(ctx, username)
 	if err != nil {
 		log.Error("failed to get user", slog.String("error", err.Error()))
+		s.registerFailure(ctx, log, subjects...)
 		return nil, ErrAccountNotFound
 	}
 
@@ -116,32 +282,141 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (*en
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
 		log.Error("invalid password")
+		s.registerFailure(ctx, log, subjects...)
 		return nil, ErrBadCredentials
 	}
 
-	// Generate access token
-	accessToken, err := s.generateAccessToken()
+	s.resetAttempts(ctx, log, subjects[0])
+
+	err = accountStatusError(user.Status)
 	if err != nil {
-		log.Error("failed to generate access token", slog.String("error", err.Error()))
+		log.Info("account is not active", slog.String("status", user.Status))
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, log, user, client)
+}
+
+// completeLogin issues tokens to an authenticated user or,
+// when the second factor is enabled, a challenge for LoginVerifyTOTP
+func (s *AuthService) completeLogin(ctx context.Context, log *slog.Logger, user *entity.User, client entity.ClientInfo) (*entity.LoginResponse, error) {
+	// Tokens are only issued after the second factor when it is enabled
+	totp, err := s.totpRepo.GetByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, ErrTOTPNotEnrolled) {
+		log.Error("failed to get totp", slog.String("error", err.Error()))
 		return nil, err
 	}
+	if err == nil && totp.Enabled() {
+		challengeID, err := s.createLoginChallenge(ctx, user.ID, client)
+		if err != nil {
+			log.Error("failed to create login challenge", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		log.Info("second factor required")
+		return &entity.LoginResponse{ID: user.ID, ChallengeID: challengeID}, nil
+	}
 
-	// Store access token in database
-	_, err = s.tokenRepo.CreateAccessToken(ctx, user.ID, accessToken)
+	resp, err := s.startSession(ctx, user, client)
 	if err != nil {
-		log.Error("failed to store access token", slog.String("error", err.Error()))
+		log.Error("failed to start session", slog.String("error", err.Error()))
 		return nil, err
 	}
 
 	log.Info("login successful")
-	return &entity.LoginResponse{
-		ID:    int(user.ID),
-		Token: accessToken,
-	}, nil
+	return resp, nil
+}
+
+// startSession issues access and refresh tokens within a new token family
+func (s *AuthService) startSession(ctx context.Context, user *entity.User, client entity.ClientInfo) (*entity.LoginResponse, error) {
+	familyID, err := s.generateFamilyID()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, familyID, client)
+}
+
+// Refresh rotates a refresh token and issues a new token pair.
+// Presenting an already rotated refresh token revokes the whole token family.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, client entity.ClientInfo) (*entity.LoginResponse, error) {
+	const op = "AuthService.Refresh"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("refresh attempt")
+
+	// Find refresh token
+	token, err := s.tokenRepo.GetRefreshTokenByToken(ctx, refreshToken)
+	if err != nil {
+		log.Error("refresh token not found", slog.String("error", err.Error()))
+		return nil, ErrTokenNotFound
+	}
+
+	log = log.With(
+		slog.Int("user_id", token.UserID),
+		slog.String("family_id", token.FamilyID),
+	)
+
+	// A rotated token must never be presented again
+	if token.RotatedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, log, token.FamilyID)
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		log.Info("refresh token expired")
+		return nil, ErrTokenExpired
+	}
+
+	// Rotate atomically so concurrent refreshes can't both succeed
+	rotated, err := s.tokenRepo.RotateRefreshToken(ctx, token.ID)
+	if err != nil {
+		log.Error("failed to rotate refresh token", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if !rotated {
+		return nil, s.revokeReusedFamily(ctx, log, token.FamilyID)
+	}
+
+	// Get current user state for the new access token
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, ErrAccountNotFound
+	}
+
+	err = accountStatusError(user.Status)
+	if err != nil {
+		log.Info("account is not active", slog.String("status", user.Status))
+		if errors.Is(err, ErrAccountNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	// The new access token replaces the previous one of this session
+	err = s.tokenRepo.DeleteAccessTokensByFamily(ctx, token.FamilyID)
+	if err != nil {
+		log.Error("failed to delete previous access tokens", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// Issue new tokens within the same family
+	resp, err := s.issueTokens(ctx, user, token.FamilyID, client)
+	if err != nil {
+		log.Error("failed to issue tokens", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Info("refresh successful")
+	return resp, nil
 }
 
-// Register handles user registration
-func (s *AuthService) Register(ctx context.Context, username, password string) error {
+// Register handles user registration.
+// With activation required the account stays pending until ActivateAccount succeeds.
+func (s *AuthService) Register(ctx context.Context, username, password string) (*entity.User, error) {
 	const op = "AuthService.Register"
 
 	log := s.log.With(
@@ -151,28 +426,152 @@ func (s *AuthService) Register(ctx context.Context, username, password string) e
 
 	log.Info("registration attempt")
 
+	if reservedUsername(username) {
+		log.Error("username is reserved")
+		return nil, ErrReservedUsername
+	}
+
 	// Check if user already exists
 	_, err := s.userRepo.GetByUsername(ctx, username)
 	if err == nil {
 		log.Error("user already exists")
-		return ErrAccountAlreadyExists
+		return nil, ErrAccountAlreadyExists
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		log.Error("failed to hash password", slog.String("error", err.Error()))
-		return err
+		return nil, err
+	}
+
+	status := entity.StatusActive
+	if s.activation.Required {
+		status = entity.StatusPending
 	}
 
 	// Create user (with default 'user' role)
-	_, err = s.userRepo.Create(ctx, username, string(hashedPassword))
+	user, err := s.userRepo.Create(ctx, username, string(hashedPassword), status)
 	if err != nil {
 		log.Error("failed to create user", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// The account exists anyway, a failed delivery can be retried with a resend
+	if user.Status == entity.StatusPending {
+		err = s.sendActivationCode(ctx, user)
+		if err != nil {
+			log.Error("failed to send activation code", slog.String("error", err.Error()))
+		}
+	}
+
+	log.Info("registration successful", slog.String("status", user.Status))
+	return user, nil
+}
+
+// ActivateAccount activates a pending account using the activation code
+func (s *AuthService) ActivateAccount(ctx context.Context, username, code string) error {
+	const op = "AuthService.ActivateAccount"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	log.Info("activation attempt")
+
+	// Refuse attempts while the username is locked out
+	subject := "activate:user:" + username
+	err := s.checkLockout(ctx, subject)
+	if err != nil {
+		log.Warn("activation locked out", slog.String("error", err.Error()))
+		return err
+	}
+
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil || user.Status != entity.StatusPending {
+		log.Info("no pending account")
+		s.registerFailure(ctx, log, subject)
+		return ErrVerificationFailed
+	}
+
+	log = log.With(slog.Int("user_id", user.ID))
+
+	// Check if code exists
+	key := activationKey(user.ID)
+	var expected string
+	err = s.authCodes.Get(ctx, key, &expected)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCacheNotFound):
+			log.Info("activation code not found")
+			s.registerFailure(ctx, log, subject)
+			return ErrVerificationFailed
+		default:
+			log.Error("get from cache error", slog.String("error", err.Error()))
+			return err
+		}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+		log.Info("invalid activation code")
+		s.registerFailure(ctx, log, subject)
+		return ErrVerificationFailed
+	}
+
+	s.resetAttempts(ctx, log, subject)
+
+	// Codes are single use
+	_, err = s.authCodes.Del(ctx, key)
+	if err != nil {
+		log.Error("failed to delete activation code from cache", slog.String("error", err.Error()))
+		return err
+	}
+
+	err = s.userRepo.SetStatus(ctx, user.ID, entity.StatusActive)
+	if err != nil {
+		log.Error("failed to activate account", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("account activated")
+	return nil
+}
+
+// ResendActivationCode sends a new activation code to a pending account.
+// Unknown or already active accounts are not reported to avoid account enumeration.
+func (s *AuthService) ResendActivationCode(ctx context.Context, username string) error {
+	const op = "AuthService.ResendActivationCode"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	log.Info("activation code resend requested")
+
+	// Every resend counts towards the limit so codes can't be flooded
+	subject := "activate:resend:" + username
+	err := s.checkLockout(ctx, subject)
+	if err != nil {
+		log.Warn("activation resend locked out", slog.String("error", err.Error()))
+		return err
+	}
+	s.registerFailure(ctx, log, subject)
+
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil || user.Status != entity.StatusPending {
+		log.Info("no pending account")
+		return nil
+	}
+
+	err = s.sendActivationCode(ctx, user)
+	if err != nil {
+		log.Error("failed to send activation code", slog.String("error", err.Error()))
 		return err
 	}
 
-	log.Info("registration successful")
+	log.Info("activation code sent", slog.Int("user_id", user.ID))
 	return nil
 }
 
@@ -182,13 +581,18 @@ func (s *AuthService) Logout(ctx context.Context, accessToken string) error {
 
 	log := s.log.With(
 		slog.String("op", op),
-		slog.String("token", accessToken),
 	)
 
 	log.Info("logout attempt")
 
+	key, err := s.accessTokenKey(accessToken)
+	if err != nil {
+		log.Error("invalid access token", slog.String("error", err.Error()))
+		return ErrTokenNotFound
+	}
+
 	// Find access token
-	token, err := s.tokenRepo.GetAccessTokenByToken(ctx, accessToken)
+	token, err := s.tokenRepo.GetAccessTokenByToken(ctx, key)
 	if err != nil {
 		log.Error("token not found", slog.String("error", err.Error()))
 		return ErrTokenNotFound
@@ -201,200 +605,585 @@ func (s *AuthService) Logout(ctx context.Context, accessToken string) error {
 		return err
 	}
 
+	// Revoke the refresh tokens issued together with it
+	if token.FamilyID != "" {
+		err = s.tokenRepo.DeleteTokenFamily(ctx, token.FamilyID)
+		if err != nil {
+			log.Error("failed to delete token family", slog.String("error", err.Error()))
+			return err
+		}
+	}
+
 	log.Info("logout successful", slog.Int("user_id", token.UserID))
 	return nil
 }
 
-// GenerateAuthCode generates an authentication code for a user
-func (s *AuthService) GenerateAuthCode(ctx context.Context, userID int) (string, error) {
+// GenerateAuthCode generates a verification code for a Telegram user.
+// Only services holding a valid service token, such as the bot, may generate codes.
+func (s *AuthService) GenerateAuthCode(ctx context.Context, serviceToken string, tgUserID int) (string, error) {
 	const op = "AuthService.GenerateAuthCode"
 
 	log := s.log.With(
 		slog.String("op", op),
-		slog.Int("user_id", userID),
+		slog.Int("tg_user_id", tgUserID),
 	)
 
 	log.Info("generating auth code")
 
-	// Generate random code
-	code, err := s.generateRandomCode()
+	service, err := s.authorizeService(ctx, log, serviceToken, entity.ScopeGenerateAuthCode)
 	if err != nil {
-		log.Error("failed to generate auth code", slog.String("error", err.Error()))
 		return "", err
 	}
 
-	// Insert to cache
-	go func() {
-		err := s.authCodes.Set(ctx, code, userID, s.authCode.TTL)
-		log.Error("failed to set auth code in cache", slog.String("error", err.Error()))
-	}()
+	log = log.With(slog.String("service", service.ServiceName))
+
+	// A new code replaces the previous one of the Telegram user
+	indexKey := authCodeIndexKey(tgUserID)
+	var previous string
+	err = s.authCodes.Get(ctx, indexKey, &previous)
+	if err == nil {
+		_, err = s.authCodes.Del(ctx, authCodeKey(previous))
+	}
+	if err != nil && !errors.Is(err, ErrCacheNotFound) {
+		log.Error("failed to drop previous auth code", slog.String("error", err.Error()))
+		return "", err
+	}
+
+	record := map[string]any{
+		"tg_user_id":    tgUserID,
+		"service":       service.ServiceName,
+		"attempts_left": s.authCode.MaxAttempts,
+		"created_at":    time.Now().Unix(),
+	}
+
+	// Retry on the unlikely collision with a live code of another user
+	for range _authCodeRetries {
+		code, err := s.generateRandomCode(s.authCode.Length)
+		if err != nil {
+			log.Error("failed to generate auth code", slog.String("error", err.Error()))
+			return "", err
+		}
+
+		stored, err := s.authCodes.SetHashNX(ctx, authCodeKey(code), record, s.authCode.TTL)
+		if err != nil {
+			log.Error("failed to store auth code", slog.String("error", err.Error()))
+			return "", err
+		}
+		if !stored {
+			continue
+		}
+
+		err = s.authCodes.Set(ctx, indexKey, code, s.authCode.TTL)
+		if err != nil {
+			log.Error("failed to store auth code index", slog.String("error", err.Error()))
+			return "", err
+		}
+
+		log.Info("auth code generated")
+		return code, nil
+	}
 
-	log.Info("auth code generated", slog.String("code", code))
-	return code, nil
+	log.Error("failed to find a free auth code")
+	return "", ErrAuthCodeCollision
 }
 
-// Verify verifies an authentication code
-func (s *AuthService) Verify(ctx context.Context, userID int, code string) (bool, error) {
+// Verify links the Telegram user who requested the code to the token owner
+func (s *AuthService) Verify(ctx context.Context, accessToken, code string) (bool, error) {
 	const op = "AuthService.Verify"
 
 	log := s.log.With(
 		slog.String("op", op),
-		slog.Int("user_id", userID),
-		slog.String("code", code),
 	)
 
 	log.Info("verifying auth code")
 
-	// Check if code exists
-	var tgUserID int
-	err := s.authCodes.Get(ctx, code, &tgUserID)
+	current, err := s.activeAccessToken(ctx, log, accessToken)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrCacheNotFound):
-			log.Error("auth code not found")
-			return false, ErrVerificationFailed
-		default:
-			log.Error("get from cache error", slog.String("error", err.Error()))
-			return false, err
-		}
+		return false, err
 	}
 
-	// Compare ids
-	tgConn, err := s.tgConn.GetByUserID(ctx, userID)
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	record, err := s.claimAuthCode(ctx, log, current.UserID, code)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrTgConnNotFound):
-			tgConn, err = s.tgConn.Create(ctx, userID, tgUserID)
-			if err != nil {
-				log.Error("failed to create tg connection", slog.String("error", err.Error()))
-				return false, ErrVerificationFailed
-			}
-		default:
-			log.Error("failed to get tg connection", slog.String("error", err.Error()))
-			return false, ErrVerificationFailed
-		}
+		return false, err
 	}
 
-	if tgUserID != tgConn.TgUserID {
-		log.Error("invalid user_id")
+	log = log.With(slog.Int("tg_user_id", record.TgUserID), slog.String("service", record.Service))
+
+	// An existing link can only be moved with RelinkTelegram
+	tgConn, err := s.tgConn.GetByUserID(ctx, current.UserID)
+	switch {
+	case err == nil && tgConn.TgUserID != record.TgUserID:
+		log.Info("account is linked to another telegram user")
 		return false, ErrVerificationFailed
+	case err != nil && !errors.Is(err, ErrTgConnNotFound):
+		log.Error("failed to get tg connection", slog.String("error", err.Error()))
+		return false, err
 	}
 
-	_, err = s.authCodes.Del(ctx, code)
+	err = s.consumeAuthCode(ctx, code)
 	if err != nil {
-		log.Error("failed to delete code from cache", slog.String("error", err.Error()))
+		log.Info("auth code already used", slog.String("error", err.Error()))
 		return false, err
 	}
 
+	if tgConn == nil {
+		_, err = s.tgConn.Create(ctx, current.UserID, record.TgUserID)
+		if err != nil {
+			log.Error("failed to create tg connection", slog.String("error", err.Error()))
+			return false, ErrVerificationFailed
+		}
+	}
+
 	log.Info("auth code verified")
 	return true, nil
 }
 
-// GenerateServiceToken generates a service token for a service
-func (s *AuthService) GenerateServiceToken(ctx context.Context, serviceName string) (string, error) {
-	const op = "AuthService.GenerateServiceToken"
-
-	log := s.log.With(
-		slog.String("op", op),
-		slog.String("service_name", serviceName),
-	)
-
-	log.Info("generating service token")
+// claimAuthCode takes one attempt of the auth code for the user.
+// Unknown codes count towards the lockout of the user.
+func (s *AuthService) claimAuthCode(ctx context.Context, log *slog.Logger, userID int, code string) (*entity.AuthCodeRecord, error) {
+	subject := "verify:user:" + strconv.Itoa(userID)
+	err := s.checkLockout(ctx, subject)
+	if err != nil {
+		log.Warn("verification locked out", slog.String("error", err.Error()))
+		return nil, err
+	}
 
-	// Check if service token already exists
-	existingToken, err := s.tokenRepo.GetServiceTokenByServiceName(ctx, serviceName)
-	if err == nil {
-		// If token exists, return it
-		log.Info("service token already exists")
-		return existingToken.Token, nil
+	fields, err := s.authCodes.ClaimAttempt(ctx, authCodeKey(code), "attempts_left")
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCacheNotFound):
+			log.Info("auth code not found")
+			s.registerFailure(ctx, log, subject)
+			return nil, ErrVerificationFailed
+		default:
+			log.Error("get from cache error", slog.String("error", err.Error()))
+			return nil, err
+		}
 	}
 
-	// Generate new token
-	serviceToken, err := s.generateServiceToken()
+	s.resetAttempts(ctx, log, subject)
+
+	record, err := entity.ParseAuthCodeRecord(fields)
 	if err != nil {
-		log.Error("failed to generate service token", slog.String("error", err.Error()))
-		return "", err
+		log.Error("malformed auth code record", slog.String("error", err.Error()))
+		return nil, ErrVerificationFailed
 	}
 
-	// Store service token in database
-	_, err = s.tokenRepo.CreateServiceToken(ctx, serviceName, serviceToken)
+	return record, nil
+}
+
+// consumeAuthCode removes the auth code, only one of concurrent claims succeeds
+func (s *AuthService) consumeAuthCode(ctx context.Context, code string) error {
+	deleted, err := s.authCodes.Del(ctx, authCodeKey(code))
 	if err != nil {
-		log.Error("failed to store service token", slog.String("error", err.Error()))
-		return "", err
+		return err
+	}
+	if deleted == 0 {
+		return ErrVerificationFailed
 	}
 
-	log.Info("service token generated")
-	return serviceToken, nil
+	return nil
 }
 
-// CheckAccessToken validates an access token and returns the associated user ID
-func (s *AuthService) CheckAccessToken(ctx context.Context, accessToken string) (int, error) {
+// CheckAccessToken validates an access token and returns the associated user ID and role
+func (s *AuthService) CheckAccessToken(ctx context.Context, accessToken string) (int, string, error) {
 	const op = "AuthService.CheckAccessToken"
 
 	log := s.log.With(
 		slog.String("op", op),
-		slog.String("token", accessToken),
 	)
 
 	log.Info("checking access token")
 
-	// Find access token in database
-	token, err := s.tokenRepo.GetAccessTokenByToken(ctx, accessToken)
+	token, err := s.activeAccessToken(ctx, log, accessToken)
 	if err != nil {
-		log.Error("access token not found", slog.String("error", err.Error()))
-		return 0, ErrTokenNotFound
+		return 0, "", err
 	}
 
 	log.Info("access token validated", slog.Int("user_id", token.UserID))
-	return token.UserID, nil
+	return token.UserID, token.Role, nil
 }
 
-// CheckServiceToken validates a service token
-func (s *AuthService) CheckServiceToken(ctx context.Context, serviceToken string) (bool, error) {
-	const op = "AuthService.CheckServiceToken"
+// ListSessions returns the active sessions of the access token owner
+func (s *AuthService) ListSessions(ctx context.Context, accessToken string) ([]entity.Session, error) {
+	const op = "AuthService.ListSessions"
 
 	log := s.log.With(
 		slog.String("op", op),
-		slog.String("token", serviceToken),
 	)
 
-	log.Info("checking service token")
+	log.Info("listing sessions")
 
-	// Find service token in database
-	_, err := s.tokenRepo.GetServiceTokenByToken(ctx, serviceToken)
+	current, err := s.activeAccessToken(ctx, log, accessToken)
 	if err != nil {
-		log.Error("service token not found", slog.String("error", err.Error()))
-		return false, nil
+		return nil, err
 	}
 
-	log.Info("service token validated")
-	return true, nil
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	tokens, err := s.tokenRepo.GetAccessTokensByUserID(ctx, current.UserID)
+	if err != nil {
+		log.Error("failed to get access tokens", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	now := time.Now()
+	sessions := make([]entity.Session, 0, len(tokens))
+	for i := range tokens {
+		token := &tokens[i]
+		if s.isAccessTokenExpired(token, now) {
+			continue
+		}
+
+		sessions = append(sessions, entity.Session{
+			ID:         token.ID,
+			UserAgent:  token.UserAgent,
+			IP:         token.IP,
+			CreatedAt:  token.CreatedAt,
+			LastUsedAt: token.LastUsedAt,
+			Current:    token.ID == current.ID,
+		})
+	}
+
+	log.Info("sessions listed", slog.Int("count", len(sessions)))
+	return sessions, nil
 }
 
-// GetRole gets the role of a user
-func (s *AuthService) GetRole(ctx context.Context, userID int) (string, error) {
-	const op = "AuthService.GetRole"
+// RevokeSession revokes one session of the access token owner
+func (s *AuthService) RevokeSession(ctx context.Context, accessToken string, sessionID int) error {
+	const op = "AuthService.RevokeSession"
 
 	log := s.log.With(
 		slog.String("op", op),
-		slog.Int("user_id", userID),
+		slog.Int("session_id", sessionID),
 	)
 
-	log.Info("getting user role")
+	log.Info("revoking session")
 
-	// Get user by ID
-	user, err := s.userRepo.GetByID(ctx, userID)
+	current, err := s.activeAccessToken(ctx, log, accessToken)
 	if err != nil {
-		log.Error("failed to get user", slog.String("error", err.Error()))
-		return "", ErrAccountNotFound
+		return err
+	}
+
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	tokens, err := s.tokenRepo.GetAccessTokensByUserID(ctx, current.UserID)
+	if err != nil {
+		log.Error("failed to get access tokens", slog.String("error", err.Error()))
+		return err
+	}
+
+	// Only sessions of the same user can be revoked
+	for i := range tokens {
+		if tokens[i].ID == sessionID {
+			err = s.revokeSession(ctx, &tokens[i])
+			if err != nil {
+				log.Error("failed to revoke session", slog.String("error", err.Error()))
+				return err
+			}
+
+			log.Info("session revoked")
+			return nil
+		}
+	}
+
+	log.Info("session not found")
+	return ErrSessionNotFound
+}
+
+// RevokeAllSessions revokes every session of the access token owner,
+// optionally keeping the session the request was made with
+func (s *AuthService) RevokeAllSessions(ctx context.Context, accessToken string, keepCurrent bool) error {
+	const op = "AuthService.RevokeAllSessions"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Bool("keep_current", keepCurrent),
+	)
+
+	log.Info("revoking all sessions")
+
+	current, err := s.activeAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return err
+	}
+
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	if !keepCurrent {
+		err = s.tokenRepo.DeleteUserTokens(ctx, current.UserID)
+		if err != nil {
+			log.Error("failed to delete user tokens", slog.String("error", err.Error()))
+			return err
+		}
+
+		log.Info("all sessions revoked")
+		return nil
+	}
+
+	err = s.revokeOtherSessions(ctx, current)
+	if err != nil {
+		log.Error("failed to revoke other sessions", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("other sessions revoked")
+	return nil
+}
+
+// ChangePassword replaces the password of the access token owner,
+// optionally revoking every other session
+func (s *AuthService) ChangePassword(ctx context.Context, accessToken, oldPassword, newPassword string, revokeOtherSessions bool) error {
+	const op = "AuthService.ChangePassword"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("change password attempt")
+
+	current, err := s.activeAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return err
+	}
+
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	user, err := s.userRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return ErrAccountNotFound
+	}
+
+	// Compare passwords
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword))
+	if err != nil {
+		log.Error("invalid password")
+		return ErrBadCredentials
+	}
+
+	err = s.updatePassword(ctx, user, newPassword)
+	if err != nil {
+		log.Error("failed to update password", slog.String("error", err.Error()))
+		return err
+	}
+
+	if revokeOtherSessions {
+		err = s.revokeOtherSessions(ctx, current)
+		if err != nil {
+			log.Error("failed to revoke other sessions", slog.String("error", err.Error()))
+			return err
+		}
+	}
+
+	log.Info("password changed", slog.Bool("sessions_revoked", revokeOtherSessions))
+	return nil
+}
+
+// RequestPasswordReset sends a one-time reset code to the user.
+// Unknown usernames are not reported to avoid account enumeration.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, username string) error {
+	const op = "AuthService.RequestPasswordReset"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	log.Info("password reset requested")
+
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		log.Info("user not found", slog.String("error", err.Error()))
+		return nil
+	}
+
+	if user.Status != entity.StatusActive {
+		log.Info("account is not active", slog.String("status", user.Status))
+		return nil
+	}
+
+	log = log.With(slog.Int("user_id", user.ID))
+
+	// Generate random code
+	code, err := s.generateRandomCode(s.resetCode.Length)
+	if err != nil {
+		log.Error("failed to generate reset code", slog.String("error", err.Error()))
+		return err
+	}
+
+	// A new code replaces the previous one
+	key := passwordResetKey(user.ID)
+	err = s.authCodes.Set(ctx, key, code, s.resetCode.TTL)
+	if err != nil {
+		log.Error("failed to set reset code in cache", slog.String("error", err.Error()))
+		return err
+	}
+
+	message := "Your password reset code: " + code
+	err = s.notifier.Notify(ctx, user.ID, message)
+	if err != nil {
+		log.Error("failed to deliver reset code", slog.String("error", err.Error()))
+		if _, err := s.authCodes.Del(ctx, key); err != nil {
+			log.Error("failed to delete reset code from cache", slog.String("error", err.Error()))
+		}
+		return err
+	}
+
+	log.Info("password reset code sent")
+	return nil
+}
+
+// ConfirmPasswordReset sets a new password using a reset code and
+// invalidates every existing session of the user
+func (s *AuthService) ConfirmPasswordReset(ctx context.Context, username, code, newPassword string) error {
+	const op = "AuthService.ConfirmPasswordReset"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	log.Info("password reset attempt")
+
+	// Refuse attempts while the username is locked out
+	subject := "reset:user:" + username
+	err := s.checkLockout(ctx, subject)
+	if err != nil {
+		log.Warn("password reset locked out", slog.String("error", err.Error()))
+		return err
+	}
+
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		log.Info("user not found", slog.String("error", err.Error()))
+		s.registerFailure(ctx, log, subject)
+		return ErrInvalidResetCode
+	}
+
+	log = log.With(slog.Int("user_id", user.ID))
+
+	// Check if code exists
+	key := passwordResetKey(user.ID)
+	var expected string
+	err = s.authCodes.Get(ctx, key, &expected)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCacheNotFound):
+			log.Info("reset code not found")
+			s.registerFailure(ctx, log, subject)
+			return ErrInvalidResetCode
+		default:
+			log.Error("get from cache error", slog.String("error", err.Error()))
+			return err
+		}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+		log.Info("invalid reset code")
+		s.registerFailure(ctx, log, subject)
+		return ErrInvalidResetCode
+	}
+
+	s.resetAttempts(ctx, log, subject)
+
+	// Codes are single use
+	_, err = s.authCodes.Del(ctx, key)
+	if err != nil {
+		log.Error("failed to delete reset code from cache", slog.String("error", err.Error()))
+		return err
+	}
+
+	err = s.updatePassword(ctx, user, newPassword)
+	if err != nil {
+		log.Error("failed to update password", slog.String("error", err.Error()))
+		return err
+	}
+
+	// Whoever held the old password must lose access
+	err = s.tokenRepo.DeleteUserTokens(ctx, user.ID)
+	if err != nil {
+		log.Error("failed to revoke sessions", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("password reset successful")
+	return nil
+}
+
+// GetJWKS returns the public keys used to verify signed access tokens
+func (s *AuthService) GetJWKS(ctx context.Context) ([]entity.JWK, error) {
+	if s.signer == nil {
+		return []entity.JWK{}, nil
+	}
+	return s.signer.PublicKeys(), nil
+}
+
+// PurgeExpiredTokens removes access tokens that exceeded their absolute or idle lifetime
+// and refresh tokens past their expiry
+func (s *AuthService) PurgeExpiredTokens(ctx context.Context) (int64, error) {
+	const op = "AuthService.PurgeExpiredTokens"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	now := time.Now()
+	createdBefore, usedBefore := time.Time{}, time.Time{}
+	if s.tokenTTL.AccessTokenTTL > 0 {
+		createdBefore = now.Add(-s.tokenTTL.AccessTokenTTL)
+	}
+	if s.tokenTTL.AccessTokenIdleTTL > 0 {
+		usedBefore = now.Add(-s.tokenTTL.AccessTokenIdleTTL)
+	}
+
+	deleted, err := s.tokenRepo.DeleteExpiredAccessTokens(ctx, createdBefore, usedBefore)
+	if err != nil {
+		log.Error("failed to delete expired access tokens", slog.String("error", err.Error()))
+		return 0, err
+	}
+
+	if s.tokenTTL.RefreshTokenTTL > 0 {
+		refreshDeleted, err := s.tokenRepo.DeleteExpiredRefreshTokens(ctx, now)
+		if err != nil {
+			log.Error("failed to delete expired refresh tokens", slog.String("error", err.Error()))
+			return deleted, err
+		}
+		deleted += refreshDeleted
+	}
+
+	if deleted > 0 {
+		log.Info("expired tokens purged", slog.Int64("count", deleted))
+	}
+	return deleted, nil
+}
+
+// GetRole gets the role of a user
+func (s *AuthService) GetRole(ctx context.Context, userID int) (string, error) {
+	const op = "AuthService.GetRole"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("user_id", userID),
+	)
+
+	log.Info("getting user role")
+
+	// Get user by ID
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return "", ErrAccountNotFound
 	}
 
 	log.Info("user role retrieved", slog.String("role", user.Role))
 	return user.Role, nil
 }
 
-// SetRole sets the role of a user
-func (s *AuthService) SetRole(ctx context.Context, userID int, role string) error {
+// SetRole sets the role of a user, optionally revoking all of their sessions
+func (s *AuthService) SetRole(ctx context.Context, userID int, role string, revokeSessions bool) error {
 	const op = "AuthService.SetRole"
 
 	log := s.log.With(
@@ -426,10 +1215,281 @@ func (s *AuthService) SetRole(ctx context.Context, userID int, role string) erro
 		return err
 	}
 
-	log.Info("user role set")
+	// Force the user to log in again with the new role
+	if revokeSessions {
+		err = s.tokenRepo.DeleteUserTokens(ctx, userID)
+		if err != nil {
+			log.Error("failed to revoke sessions", slog.String("error", err.Error()))
+			return err
+		}
+	}
+	s.invalidateSessions(ctx, userID)
+
+	log.Info("user role set", slog.Bool("sessions_revoked", revokeSessions))
 	return nil
 }
 
+// issueTokens creates a new access and refresh token pair within the token family.
+// With a signer configured the access token is a JWT referencing the stored session key.
+// Users of roles requiring 2FA get a token only accepted to enroll until they enable it.
+func (s *AuthService) issueTokens(ctx context.Context, user *entity.User, familyID string, client entity.ClientInfo) (*entity.LoginResponse, error) {
+	totpPending, err := s.totpPending(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionKey, err := s.generateAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := s.generateAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken := sessionKey
+	if s.signer != nil {
+		now := time.Now()
+		claims := entity.TokenClaims{
+			ID:          sessionKey,
+			Subject:     strconv.Itoa(user.ID),
+			Role:        user.Role,
+			IssuedAt:    now.Unix(),
+			TOTPPending: totpPending,
+		}
+		if s.tokenTTL.AccessTokenTTL > 0 {
+			claims.ExpiresAt = now.Add(s.tokenTTL.AccessTokenTTL).Unix()
+		}
+
+		accessToken, err = s.signer.Sign(claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = s.tokenRepo.CreateAccessToken(ctx, user.ID, sessionKey, familyID, client, totpPending)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.tokenTTL.RefreshTokenTTL)
+	_, err = s.tokenRepo.CreateRefreshToken(ctx, user.ID, familyID, refreshToken, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.LoginResponse{
+		ID:                     user.ID,
+		Token:                  accessToken,
+		RefreshToken:           refreshToken,
+		TOTPEnrollmentRequired: totpPending,
+	}, nil
+}
+
+// accessTokenKey maps a presented access token to the key stored in the database
+func (s *AuthService) accessTokenKey(accessToken string) (string, error) {
+	if s.signer == nil {
+		return accessToken, nil
+	}
+
+	claims, err := s.signer.Verify(accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.ID, nil
+}
+
+// activeAccessToken looks up a presented access token, checks its lifetimes and
+// slides its idle window. Tokens pending 2FA enrollment are rejected.
+func (s *AuthService) activeAccessToken(ctx context.Context, log *slog.Logger, accessToken string) (*entity.AccessToken, error) {
+	token, err := s.session(ctx, log, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.TOTPPending {
+		log.Info("access token is pending totp enrollment", slog.Int("user_id", token.UserID))
+		return nil, ErrTOTPPending
+	}
+
+	return token, nil
+}
+
+// enrollmentAccessToken is activeAccessToken that also accepts tokens pending 2FA enrollment
+func (s *AuthService) enrollmentAccessToken(ctx context.Context, log *slog.Logger, accessToken string) (*entity.AccessToken, error) {
+	return s.session(ctx, log, accessToken)
+}
+
+// session looks up the token for activeAccessToken and enrollmentAccessToken.
+// The lookup carries the role and status of the owner, a cached token needs no database query.
+func (s *AuthService) session(ctx context.Context, log *slog.Logger, accessToken string) (*entity.AccessToken, error) {
+	key, err := s.accessTokenKey(accessToken)
+	if err != nil {
+		log.Info("invalid access token", slog.String("error", err.Error()))
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrTokenNotFound
+	}
+
+	// Find access token in database
+	token, err := s.tokenRepo.GetAccessTokenByToken(ctx, key)
+	if err != nil {
+		log.Error("access token not found", slog.String("error", err.Error()))
+		return nil, ErrTokenNotFound
+	}
+
+	// Check absolute and idle lifetimes
+	if s.isAccessTokenExpired(token, time.Now()) {
+		log.Info("access token expired", slog.Int("user_id", token.UserID))
+		return nil, ErrTokenExpired
+	}
+
+	// Tokens of inactive accounts are rejected even if not revoked yet
+	err = accountStatusError(token.Status)
+	if err != nil {
+		log.Info("account is not active", slog.Int("user_id", token.UserID), slog.String("status", token.Status))
+		if errors.Is(err, ErrAccountNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	// Slide the idle window
+	err = s.tokenRepo.RenewAccessToken(ctx, token.ID)
+	if err != nil {
+		log.Error("failed to renew access token", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// invalidateSessions drops cached tokens of the user, so a new role or status applies at once
+func (s *AuthService) invalidateSessions(ctx context.Context, userID int) {
+	if c, ok := s.tokenRepo.(SessionCacheI); ok {
+		c.InvalidateUser(ctx, userID)
+	}
+}
+
+// updatePassword hashes and stores a new password for the user
+func (s *AuthService) updatePassword(ctx context.Context, user *entity.User, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.Password = string(hashedPassword)
+	return s.userRepo.Update(ctx, user)
+}
+
+// sendActivationCode generates a new activation code and delivers it to the user
+func (s *AuthService) sendActivationCode(ctx context.Context, user *entity.User) error {
+	code, err := s.generateRandomCode(s.activation.CodeLength)
+	if err != nil {
+		return err
+	}
+
+	// A new code replaces the previous one
+	err = s.authCodes.Set(ctx, activationKey(user.ID), code, s.activation.CodeTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.Notify(ctx, user.ID, "Your account activation code: "+code)
+}
+
+// revokeOtherSessions removes every session of the user except the current one
+func (s *AuthService) revokeOtherSessions(ctx context.Context, current *entity.AccessToken) error {
+	tokens, err := s.tokenRepo.GetAccessTokensByUserID(ctx, current.UserID)
+	if err != nil {
+		return err
+	}
+
+	for i := range tokens {
+		token := &tokens[i]
+		if token.ID == current.ID || (current.FamilyID != "" && token.FamilyID == current.FamilyID) {
+			continue
+		}
+
+		err = s.revokeSession(ctx, token)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// revokeSession removes a session together with the refresh tokens of its family
+func (s *AuthService) revokeSession(ctx context.Context, token *entity.AccessToken) error {
+	if token.FamilyID != "" {
+		return s.tokenRepo.DeleteTokenFamily(ctx, token.FamilyID)
+	}
+	return s.tokenRepo.DeleteAccessToken(ctx, token.ID)
+}
+
+// revokeReusedFamily revokes every token of a family after a refresh token reuse
+func (s *AuthService) revokeReusedFamily(ctx context.Context, log *slog.Logger, familyID string) error {
+	log.Warn("refresh token reuse detected, revoking token family")
+
+	err := s.tokenRepo.DeleteTokenFamily(ctx, familyID)
+	if err != nil {
+		log.Error("failed to revoke token family", slog.String("error", err.Error()))
+		return err
+	}
+
+	return ErrTokenReused
+}
+
+// isAccessTokenExpired reports whether the token exceeded its absolute or idle lifetime.
+// A zero lifetime disables the corresponding check.
+func (s *AuthService) isAccessTokenExpired(token *entity.AccessToken, now time.Time) bool {
+	if s.tokenTTL.AccessTokenTTL > 0 && now.Sub(token.CreatedAt) > s.tokenTTL.AccessTokenTTL {
+		return true
+	}
+	if s.tokenTTL.AccessTokenIdleTTL > 0 && now.Sub(token.LastUsedAt) > s.tokenTTL.AccessTokenIdleTTL {
+		return true
+	}
+	return false
+}
+
+// accountStatusError returns the error refusing access to an account that is not active
+func accountStatusError(status string) error {
+	switch status {
+	case entity.StatusActive:
+		return nil
+	case entity.StatusPending:
+		return ErrNotActivated
+	case entity.StatusBlocked:
+		return ErrAccountBlocked
+	default:
+		return ErrAccountNotFound
+	}
+}
+
+// authCodeKey returns the cache key holding the record of an auth code
+func authCodeKey(code string) string {
+	return "auth_code:" + code
+}
+
+// authCodeIndexKey returns the cache key holding the live auth code of a Telegram user
+func authCodeIndexKey(tgUserID int) string {
+	return "auth_code:tg:" + strconv.Itoa(tgUserID)
+}
+
+// activationKey returns the cache key holding the activation code of a user
+func activationKey(userID int) string {
+	return "activation:" + strconv.Itoa(userID)
+}
+
+// passwordResetKey returns the cache key holding the password reset code of a user
+func passwordResetKey(userID int) string {
+	return "password_reset:" + strconv.Itoa(userID)
+}
+
 // generateAccessToken generates a random access token
 func (s *AuthService) generateAccessToken() (string, error) {
 	bytes := make([]byte, 32)
@@ -439,6 +1499,15 @@ func (s *AuthService) generateAccessToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// generateFamilyID generates a random token family identifier
+func (s *AuthService) generateFamilyID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
 // generateServiceToken generates a random service token
 func (s *AuthService) generateServiceToken() (string, error) {
 	bytes := make([]byte, 32)
@@ -448,12 +1517,12 @@ func (s *AuthService) generateServiceToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-// generateRandomCode generates a random authentication code consisting of digits only
-func (s *AuthService) generateRandomCode() (string, error) {
-	code := make([]byte, s.authCode.Length)
+// generateRandomCode generates a random code of the given length consisting of digits only
+func (s *AuthService) generateRandomCode(length int) (string, error) {
+	code := make([]byte, length)
 
 	// Fill the byte slice with random digits (0-9)
-	for i := range s.authCode.Length {
+	for i := range length {
 		num, err := rand.Int(rand.Reader, big.NewInt(10))
 		if err != nil {
 			return "", err
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+)
+
+const _maxDisplayNameLength = 100
+
+// localeRe accepts BCP 47 tags made of a language and optional script and region, e.g. "en", "ru-RU", "sr-Latn-RS"
+var localeRe = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z][a-z]{3})?(-([A-Z]{2}|[0-9]{3}))?$`)
+
+// GetMe returns the account, Telegram link and profile of the token owner
+func (s *AuthService) GetMe(ctx context.Context, accessToken string) (*entity.Me, error) {
+	const op = "AuthService.GetMe"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	token, err := s.activeAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, ErrTokenNotFound
+	}
+
+	log = log.With(slog.Int("user_id", user.ID))
+
+	me := &entity.Me{User: *user}
+
+	conn, err := s.tgConn.GetByUserID(ctx, user.ID)
+	switch {
+	case err == nil:
+		me.TgConnection = conn
+	case !errors.Is(err, ErrTgConnNotFound):
+		log.Error("failed to get tg connection", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	profile, err := s.profile(ctx, user.ID)
+	if err != nil {
+		log.Error("failed to get profile", slog.String("error", err.Error()))
+		return nil, err
+	}
+	me.Profile = *profile
+
+	return me, nil
+}
+
+// UpdateProfile changes the profile fields of the token owner
+func (s *AuthService) UpdateProfile(ctx context.Context, accessToken string, update entity.ProfileUpdate) (*entity.Profile, error) {
+	const op = "AuthService.UpdateProfile"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("updating profile")
+
+	current, err := s.activeAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	profile, err := s.profile(ctx, current.UserID)
+	if err != nil {
+		log.Error("failed to get profile", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	err = s.applyProfileUpdate(profile, update)
+	if err != nil {
+		log.Info("invalid profile", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	err = s.profileRepo.Upsert(ctx, profile)
+	if err != nil {
+		log.Error("failed to save profile", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Info("profile updated")
+	return profile, nil
+}
+
+// profile returns the stored profile of a user or an empty one
+func (s *AuthService) profile(ctx context.Context, userID int) (*entity.Profile, error) {
+	profile, err := s.profileRepo.GetByUserID(ctx, userID)
+	if errors.Is(err, ErrProfileNotFound) {
+		return &entity.Profile{UserID: userID}, nil
+	}
+	return profile, err
+}
+
+func (s *AuthService) applyProfileUpdate(profile *entity.Profile, update entity.ProfileUpdate) error {
+	if update.DisplayName != nil {
+		name := strings.TrimSpace(*update.DisplayName)
+		if utf8.RuneCountInString(name) > _maxDisplayNameLength {
+			return fmt.Errorf("%w: display name is longer than %d characters", ErrInvalidProfile, _maxDisplayNameLength)
+		}
+		profile.DisplayName = name
+	}
+
+	if update.AvatarURL != nil {
+		err := s.validateAvatarURL(*update.AvatarURL)
+		if err != nil {
+			return err
+		}
+		profile.AvatarURL = *update.AvatarURL
+	}
+
+	if update.Timezone != nil {
+		if *update.Timezone != "" {
+			_, err := time.LoadLocation(*update.Timezone)
+			if err != nil || *update.Timezone == "Local" {
+				return fmt.Errorf("%w: unknown timezone", ErrInvalidProfile)
+			}
+		}
+		profile.Timezone = *update.Timezone
+	}
+
+	if update.Locale != nil {
+		if *update.Locale != "" && !localeRe.MatchString(*update.Locale) {
+			return fmt.Errorf("%w: malformed locale", ErrInvalidProfile)
+		}
+		profile.Locale = *update.Locale
+	}
+
+	return nil
+}
+
+// validateAvatarURL accepts http(s) URLs, limited to uploaded media when a prefix is configured
+func (s *AuthService) validateAvatarURL(avatarURL string) error {
+	if avatarURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(avatarURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Contains(u.Path, "..") {
+		return fmt.Errorf("%w: malformed avatar url", ErrInvalidProfile)
+	}
+
+	prefix := s.profilePolicy.AvatarURLPrefix
+	if prefix != "" && !strings.HasPrefix(avatarURL, prefix) {
+		return fmt.Errorf("%w: avatar must be uploaded through the media service", ErrInvalidProfile)
+	}
+
+	return nil
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+)
+
+// CheckPermission reports whether the role of a user grants the permission
+func (s *AuthService) CheckPermission(ctx context.Context, userID int, permission string) (bool, error) {
+	const op = "AuthService.CheckPermission"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("user_id", userID),
+		slog.String("permission", permission),
+	)
+
+	log.Info("checking permission")
+
+	allowed, err := s.roleRepo.HasPermission(ctx, userID, permission)
+	if err != nil {
+		log.Error("failed to check permission", slog.String("error", err.Error()))
+		return false, err
+	}
+
+	// Roles requiring 2FA grant nothing until it is enabled
+	if allowed && len(s.totpPolicy.RequiredRoles) > 0 {
+		allowed, err = s.totpSatisfied(ctx, userID)
+		if err != nil {
+			log.Error("failed to check totp", slog.String("error", err.Error()))
+			return false, err
+		}
+	}
+
+	log.Info("permission checked", slog.Bool("allowed", allowed))
+	return allowed, nil
+}
+
+// ListRoles returns every role with its permissions
+func (s *AuthService) ListRoles(ctx context.Context) ([]entity.Role, error) {
+	const op = "AuthService.ListRoles"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	roles, err := s.roleRepo.GetAll(ctx)
+	if err != nil {
+		log.Error("failed to get roles", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	for i := range roles {
+		roles[i].Permissions, err = s.roleRepo.GetPermissionsByRoleID(ctx, roles[i].ID)
+		if err != nil {
+			log.Error("failed to get role permissions", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+
+	return roles, nil
+}
+
+// CreateRole creates a role without permissions
+func (s *AuthService) CreateRole(ctx context.Context, title string) (*entity.Role, error) {
+	const op = "AuthService.CreateRole"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("role", title),
+	)
+
+	log.Info("creating role")
+
+	// Check if role already exists
+	_, err := s.roleRepo.GetByTitle(ctx, title)
+	if err == nil {
+		log.Error("role already exists")
+		return nil, ErrRoleAlreadyExists
+	}
+
+	role, err := s.roleRepo.Create(ctx, title)
+	if err != nil {
+		log.Error("failed to create role", slog.String("error", err.Error()))
+		return nil, err
+	}
+	role.Permissions = []string{}
+
+	log.Info("role created")
+	return role, nil
+}
+
+// DeleteRole deletes a role that is not assigned to any user
+func (s *AuthService) DeleteRole(ctx context.Context, title string) error {
+	const op = "AuthService.DeleteRole"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("role", title),
+	)
+
+	log.Info("deleting role")
+
+	role, err := s.roleRepo.GetByTitle(ctx, title)
+	if err != nil {
+		log.Error("role does not exist", slog.String("error", err.Error()))
+		return ErrInvalidRole
+	}
+
+	// Accounts cascade on role deletion, never drop a role in use
+	count, err := s.roleRepo.CountUsers(ctx, role.ID)
+	if err != nil {
+		log.Error("failed to count role users", slog.String("error", err.Error()))
+		return err
+	}
+	if count > 0 {
+		log.Error("role is in use", slog.Int("users", count))
+		return ErrRoleInUse
+	}
+
+	err = s.roleRepo.Delete(ctx, role.ID)
+	if err != nil {
+		log.Error("failed to delete role", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("role deleted")
+	return nil
+}
+
+// ListPermissions returns every known permission
+func (s *AuthService) ListPermissions(ctx context.Context) ([]entity.Permission, error) {
+	const op = "AuthService.ListPermissions"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	permissions, err := s.roleRepo.GetAllPermissions(ctx)
+	if err != nil {
+		log.Error("failed to get permissions", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// CreatePermission creates a permission that can be granted to roles
+func (s *AuthService) CreatePermission(ctx context.Context, title, description string) (*entity.Permission, error) {
+	const op = "AuthService.CreatePermission"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("permission", title),
+	)
+
+	log.Info("creating permission")
+
+	// Check if permission already exists
+	_, err := s.roleRepo.GetPermissionByTitle(ctx, title)
+	if err == nil {
+		log.Error("permission already exists")
+		return nil, ErrPermissionExists
+	}
+
+	permission, err := s.roleRepo.CreatePermission(ctx, title, description)
+	if err != nil {
+		log.Error("failed to create permission", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Info("permission created")
+	return permission, nil
+}
+
+// DeletePermission deletes a permission and revokes it from every role
+func (s *AuthService) DeletePermission(ctx context.Context, title string) error {
+	const op = "AuthService.DeletePermission"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("permission", title),
+	)
+
+	log.Info("deleting permission")
+
+	permission, err := s.roleRepo.GetPermissionByTitle(ctx, title)
+	if err != nil {
+		log.Error("permission does not exist", slog.String("error", err.Error()))
+		return ErrPermissionNotFound
+	}
+
+	err = s.roleRepo.DeletePermission(ctx, permission.ID)
+	if err != nil {
+		log.Error("failed to delete permission", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("permission deleted")
+	return nil
+}
+
+// GrantPermission grants a permission to a role
+func (s *AuthService) GrantPermission(ctx context.Context, role, permission string) error {
+	const op = "AuthService.GrantPermission"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("role", role),
+		slog.String("permission", permission),
+	)
+
+	log.Info("granting permission")
+
+	roleEntity, permissionEntity, err := s.getRolePermission(ctx, log, role, permission)
+	if err != nil {
+		return err
+	}
+
+	err = s.roleRepo.AddPermission(ctx, roleEntity.ID, permissionEntity.ID)
+	if err != nil {
+		log.Error("failed to grant permission", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("permission granted")
+	return nil
+}
+
+// RevokePermission revokes a permission from a role
+func (s *AuthService) RevokePermission(ctx context.Context, role, permission string) error {
+	const op = "AuthService.RevokePermission"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("role", role),
+		slog.String("permission", permission),
+	)
+
+	log.Info("revoking permission")
+
+	roleEntity, permissionEntity, err := s.getRolePermission(ctx, log, role, permission)
+	if err != nil {
+		return err
+	}
+
+	err = s.roleRepo.RemovePermission(ctx, roleEntity.ID, permissionEntity.ID)
+	if err != nil {
+		log.Error("failed to revoke permission", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("permission revoked")
+	return nil
+}
+
+// getRolePermission looks up a role and a permission by title
+func (s *AuthService) getRolePermission(ctx context.Context, log *slog.Logger, role, permission string) (*entity.Role, *entity.Permission, error) {
+	roleEntity, err := s.roleRepo.GetByTitle(ctx, role)
+	if err != nil {
+		log.Error("role does not exist", slog.String("error", err.Error()))
+		return nil, nil, ErrInvalidRole
+	}
+
+	permissionEntity, err := s.roleRepo.GetPermissionByTitle(ctx, permission)
+	if err != nil {
+		log.Error("permission does not exist", slog.String("error", err.Error()))
+		return nil, nil, ErrPermissionNotFound
+	}
+
+	return roleEntity, permissionEntity, nil
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+)
+
+// GenerateServiceToken issues the first token of a service with the given scopes.
+// A zero ttl never expires. Services holding a valid token must rotate it instead.
+func (s *AuthService) GenerateServiceToken(ctx context.Context, serviceName string, scopes []string, ttl time.Duration) (*entity.SerivceToken, error) {
+	const op = "AuthService.GenerateServiceToken"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("service_name", serviceName),
+	)
+
+	log.Info("generating service token")
+
+	err := validateScopes(scopes)
+	if err != nil {
+		log.Info("invalid scopes", slog.Any("scopes", scopes))
+		return nil, err
+	}
+
+	existing, err := s.tokenRepo.GetServiceTokenByServiceName(ctx, serviceName)
+	switch {
+	case err == nil && !existing.Expired(time.Now()):
+		log.Info("service token already exists")
+		return nil, ErrServiceTokenExists
+	case err != nil && !errors.Is(err, ErrServiceTokenNotFound):
+		log.Error("failed to get service token", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	serviceToken, err := s.createServiceToken(ctx, serviceName, scopes, ttl)
+	if err != nil {
+		log.Error("failed to store service token", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Info("service token generated", slog.Int("token_id", serviceToken.ID))
+	return serviceToken, nil
+}
+
+// RotateServiceToken issues a new token of a service with the scopes of the current one.
+// Previous tokens stay valid for the overlap window, the policy default is used when it is zero.
+// A zero ttl keeps the lifetime of the current token.
+func (s *AuthService) RotateServiceToken(ctx context.Context, serviceName string, overlap, ttl time.Duration) (*entity.SerivceToken, error) {
+	const op = "AuthService.RotateServiceToken"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("service_name", serviceName),
+	)
+
+	log.Info("rotating service token")
+
+	current, err := s.tokenRepo.GetServiceTokenByServiceName(ctx, serviceName)
+	if err != nil {
+		log.Info("failed to get service token", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if overlap == 0 {
+		overlap = s.serviceTokens.RotationOverlap
+	}
+	if ttl == 0 && current.ExpiresAt != nil {
+		ttl = current.ExpiresAt.Sub(current.CreatedAt)
+	}
+
+	serviceToken, err := s.createServiceToken(ctx, serviceName, current.Scopes, ttl)
+	if err != nil {
+		log.Error("failed to store service token", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	tokens, err := s.tokenRepo.GetAllServiceTokens(ctx)
+	if err != nil {
+		log.Error("failed to get service tokens", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// Cut every older token of the service down to the overlap window
+	now := time.Now()
+	cutoff := now.Add(overlap)
+	for i := range tokens {
+		old := &tokens[i]
+		if old.ServiceName != serviceName || old.ID == serviceToken.ID || old.Expired(now) {
+			continue
+		}
+		if old.ExpiresAt != nil && !old.ExpiresAt.After(cutoff) {
+			continue
+		}
+
+		old.ExpiresAt = &cutoff
+		err = s.tokenRepo.UpdateServiceToken(ctx, old)
+		if err != nil {
+			log.Error("failed to expire previous service token", slog.Int("token_id", old.ID), slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+
+	log.Info("service token rotated", slog.Int("token_id", serviceToken.ID), slog.Duration("overlap", overlap))
+	return serviceToken, nil
+}
+
+// RevokeServiceToken invalidates a service token immediately
+func (s *AuthService) RevokeServiceToken(ctx context.Context, id int) error {
+	const op = "AuthService.RevokeServiceToken"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("token_id", id),
+	)
+
+	log.Info("revoking service token")
+
+	err := s.tokenRepo.DeleteServiceToken(ctx, id)
+	if err != nil {
+		log.Info("failed to delete service token", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("service token revoked")
+	return nil
+}
+
+// ListServiceTokens returns every service token, including expired ones
+func (s *AuthService) ListServiceTokens(ctx context.Context) ([]entity.SerivceToken, error) {
+	const op = "AuthService.ListServiceTokens"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	tokens, err := s.tokenRepo.GetAllServiceTokens(ctx)
+	if err != nil {
+		log.Error("failed to get service tokens", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// CheckServiceToken returns the service token if it exists and has not expired
+func (s *AuthService) CheckServiceToken(ctx context.Context, serviceToken string) (*entity.SerivceToken, error) {
+	const op = "AuthService.CheckServiceToken"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("checking service token")
+
+	token, err := s.tokenRepo.GetServiceTokenByToken(ctx, serviceToken)
+	switch {
+	case errors.Is(err, ErrServiceTokenNotFound):
+		log.Info("service token not found")
+		return nil, ErrInvalidServiceToken
+	case err != nil:
+		log.Error("failed to get service token", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if token.Expired(time.Now()) {
+		log.Info("service token expired", slog.String("service_name", token.ServiceName))
+		return nil, ErrInvalidServiceToken
+	}
+
+	log.Info("service token validated", slog.String("service_name", token.ServiceName))
+	return token, nil
+}
+
+// authorizeService checks that the service token is valid and was granted the scope
+func (s *AuthService) authorizeService(ctx context.Context, log *slog.Logger, serviceToken, scope string) (*entity.SerivceToken, error) {
+	token, err := s.CheckServiceToken(ctx, serviceToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.HasScope(scope) {
+		log.Warn("service token scope is not granted",
+			slog.String("service_name", token.ServiceName),
+			slog.String("scope", scope),
+		)
+		return nil, ErrScopeNotGranted
+	}
+
+	return token, nil
+}
+
+func (s *AuthService) createServiceToken(ctx context.Context, serviceName string, scopes []string, ttl time.Duration) (*entity.SerivceToken, error) {
+	token, err := s.generateServiceToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+
+	return s.tokenRepo.CreateServiceToken(ctx, serviceName, token, scopes, expiresAt)
+}
+
+func validateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !slices.Contains(entity.ServiceScopes, scope) {
+			return ErrInvalidScope
+		}
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/telegram"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// _telegramUsernamePrefix starts the usernames of accounts registered through Telegram,
+// Register rejects it
+const _telegramUsernamePrefix = "tg_"
+
+// reservedUsername reports whether the username can only be given to Telegram accounts
+func reservedUsername(username string) bool {
+	return strings.HasPrefix(strings.ToLower(username), _telegramUsernamePrefix)
+}
+
+// LoginWithTelegram logs in the user linked to a Telegram account using a
+// Login Widget payload. Unknown Telegram accounts are registered when enabled.
+func (s *AuthService) LoginWithTelegram(ctx context.Context, auth entity.TelegramAuth, client entity.ClientInfo) (*entity.LoginResponse, error) {
+	const op = "AuthService.LoginWithTelegram"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("tg_user_id", auth.ID),
+	)
+
+	log.Info("telegram login attempt")
+
+	if s.telegramLogin.BotToken == "" {
+		log.Warn("telegram bot token is not configured")
+		return nil, ErrTelegramLoginOff
+	}
+
+	// Forged payloads count towards the lockout of the client
+	var subjects []string
+	if client.IP != "" {
+		subjects = append(subjects, "login:ip:"+client.IP)
+	}
+
+	err := s.checkLockout(ctx, subjects...)
+	if err != nil {
+		log.Warn("login locked out", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	err = telegram.VerifyLogin(s.telegramLogin.BotToken, auth, s.telegramLogin.MaxAge, time.Now())
+	if err != nil {
+		log.Info("invalid telegram login", slog.String("error", err.Error()))
+		s.registerFailure(ctx, log, subjects...)
+		return nil, ErrInvalidTelegramAuth
+	}
+
+	var user *entity.User
+	conn, err := s.tgConn.GetByTgUserID(ctx, int(auth.ID))
+	switch {
+	case err == nil:
+		user, err = s.userRepo.GetByID(ctx, conn.UserID)
+		if err != nil {
+			log.Error("failed to get user", slog.String("error", err.Error()))
+			return nil, ErrAccountNotFound
+		}
+	case errors.Is(err, ErrTgConnNotFound) && s.telegramLogin.AutoRegister:
+		user, err = s.registerTelegramUser(ctx, auth.ID)
+		if err != nil {
+			log.Error("failed to register telegram user", slog.String("error", err.Error()))
+			return nil, err
+		}
+		log.Info("telegram user registered", slog.Int("user_id", user.ID))
+	case errors.Is(err, ErrTgConnNotFound):
+		log.Info("telegram account is not linked")
+		return nil, ErrAccountNotFound
+	default:
+		log.Error("failed to get tg connection", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log = log.With(slog.Int("user_id", user.ID))
+
+	err = accountStatusError(user.Status)
+	if err != nil {
+		log.Info("account is not active", slog.String("status", user.Status))
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, log, user, client)
+}
+
+// registerTelegramUser creates an active account linked to the Telegram user.
+// The password is random, the user can set one with a password reset.
+func (s *AuthService) registerTelegramUser(ctx context.Context, tgUserID int64) (*entity.User, error) {
+	password, err := s.generateAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts registered before the prefix was reserved may hold the name
+	username := _telegramUsernamePrefix + strconv.FormatInt(tgUserID, 10)
+	_, err = s.userRepo.GetByUsername(ctx, username)
+	if err == nil {
+		suffix, err := s.generateFamilyID()
+		if err != nil {
+			return nil, err
+		}
+		username += "_" + suffix[:8]
+	}
+
+	// Telegram already proved the identity, no activation is needed
+	user, err := s.userRepo.Create(ctx, username, string(hashedPassword), entity.StatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.tgConn.Create(ctx, user.ID, int(tgUserID))
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GetTelegramConnection returns the Telegram account linked to the token owner
+func (s *AuthService) GetTelegramConnection(ctx context.Context, accessToken string) (*entity.TgConnection, error) {
+	const op = "AuthService.GetTelegramConnection"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	current, err := s.activeAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.tgConn.GetByUserID(ctx, current.UserID)
+	if err != nil {
+		log.Info("failed to get tg connection", slog.Int("user_id", current.UserID), slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// UnlinkTelegram removes the Telegram account linked to the token owner
+func (s *AuthService) UnlinkTelegram(ctx context.Context, accessToken string) error {
+	const op = "AuthService.UnlinkTelegram"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("unlinking telegram")
+
+	current, err := s.activeAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return err
+	}
+
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	_, err = s.tgConn.GetByUserID(ctx, current.UserID)
+	if err != nil {
+		log.Info("failed to get tg connection", slog.String("error", err.Error()))
+		return err
+	}
+
+	err = s.tgConn.DeleteByUserID(ctx, current.UserID)
+	if err != nil {
+		log.Error("failed to delete tg connection", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("telegram unlinked")
+	return nil
+}
+
+// RelinkTelegram moves the link of the token owner to the Telegram account
+// that requested the verification code, or creates the link if there is none
+func (s *AuthService) RelinkTelegram(ctx context.Context, accessToken, code string) error {
+	const op = "AuthService.RelinkTelegram"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("relinking telegram")
+
+	current, err := s.activeAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return err
+	}
+
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	record, err := s.claimAuthCode(ctx, log, current.UserID, code)
+	if err != nil {
+		return err
+	}
+
+	tgUserID := record.TgUserID
+	log = log.With(slog.Int("tg_user_id", tgUserID))
+
+	linked, err := s.tgConn.GetByTgUserID(ctx, tgUserID)
+	switch {
+	case err == nil && linked.UserID == current.UserID:
+		log.Info("telegram account is already linked")
+		return s.consumeAuthCode(ctx, code)
+	case err == nil:
+		log.Info("telegram account is linked to another user")
+		return ErrTelegramLinked
+	case !errors.Is(err, ErrTgConnNotFound):
+		log.Error("failed to get tg connection", slog.String("error", err.Error()))
+		return err
+	}
+
+	err = s.consumeAuthCode(ctx, code)
+	if err != nil {
+		log.Info("auth code already used", slog.String("error", err.Error()))
+		return err
+	}
+
+	conn, err := s.tgConn.GetByUserID(ctx, current.UserID)
+	switch {
+	case err == nil:
+		conn.TgUserID = tgUserID
+		err = s.tgConn.Update(ctx, conn)
+	case errors.Is(err, ErrTgConnNotFound):
+		_, err = s.tgConn.Create(ctx, current.UserID, tgUserID)
+	}
+	if err != nil {
+		log.Error("failed to save tg connection", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("telegram relinked")
+	return nil
+}
+
+// ResolveTelegramUser returns the user linked to a Telegram account.
+// Only services holding a valid service token may resolve users.
+func (s *AuthService) ResolveTelegramUser(ctx context.Context, serviceToken string, tgUserID int) (int, error) {
+	const op = "AuthService.ResolveTelegramUser"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("tg_user_id", tgUserID),
+	)
+
+	_, err := s.authorizeService(ctx, log, serviceToken, entity.ScopeResolveTelegram)
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := s.tgConn.GetByTgUserID(ctx, tgUserID)
+	if err != nil {
+		log.Info("failed to get tg connection", slog.String("error", err.Error()))
+		return 0, err
+	}
+
+	return conn.UserID, nil
+}
@@ -0,0 +1,423 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/entity"
+	"github.com/Homyakadze14/PsyhoApp/AuthMicroservice/internal/lib/totp"
+)
+
+const (
+	_recoveryCodeCount = 10
+	// _recoveryCodeBytes gives 8 base32 characters per code
+	_recoveryCodeBytes = 5
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// EnrollTOTP starts two-factor enrollment of the token owner.
+// A previous unconfirmed enrollment is replaced, an enabled one must be disabled first.
+func (s *AuthService) EnrollTOTP(ctx context.Context, accessToken string) (*entity.TOTPEnrollment, error) {
+	const op = "AuthService.EnrollTOTP"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("totp enrollment attempt")
+
+	current, err := s.enrollmentAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	user, err := s.userRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, ErrAccountNotFound
+	}
+
+	existing, err := s.totpRepo.GetByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, ErrTOTPNotEnrolled) {
+		log.Error("failed to get totp", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if err == nil && existing.Enabled() {
+		log.Info("totp already enabled")
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Error("failed to generate secret", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	sealed, err := s.secretBox.Encrypt(secret)
+	if err != nil {
+		log.Error("failed to encrypt secret", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		log.Error("failed to generate recovery codes", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	err = s.totpRepo.Upsert(ctx, user.ID, sealed, hashes)
+	if err != nil {
+		log.Error("failed to save totp", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Info("totp enrollment started")
+	return &entity.TOTPEnrollment{
+		URI:           totp.URI(s.totpPolicy.Issuer, user.Username, secret),
+		Secret:        totp.Encode(secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the first code is valid.
+// Other sessions were opened without the second factor and are revoked, the current one
+// gets tokens without enrollment restriction on its next refresh.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, accessToken, code string) error {
+	const op = "AuthService.ConfirmTOTP"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("totp confirmation attempt")
+
+	current, err := s.enrollmentAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return err
+	}
+
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	subject := totpSubject(current.UserID)
+	err = s.checkLockout(ctx, subject)
+	if err != nil {
+		log.Warn("totp locked out", slog.String("error", err.Error()))
+		return err
+	}
+
+	t, err := s.totpRepo.GetByUserID(ctx, current.UserID)
+	if err != nil {
+		log.Info("failed to get totp", slog.String("error", err.Error()))
+		return err
+	}
+	if t.Enabled() {
+		log.Info("totp already enabled")
+		return ErrTOTPAlreadyEnabled
+	}
+
+	// Recovery codes don't prove the authenticator app was set up
+	ok, err := s.verifyTOTP(ctx, t, code, false)
+	if err != nil {
+		log.Error("failed to verify totp", slog.String("error", err.Error()))
+		return err
+	}
+	if !ok {
+		log.Info("invalid totp code")
+		s.registerFailure(ctx, log, subject)
+		return ErrInvalidTOTPCode
+	}
+
+	s.resetAttempts(ctx, log, subject)
+
+	err = s.totpRepo.Confirm(ctx, current.UserID)
+	if err != nil {
+		log.Error("failed to confirm totp", slog.String("error", err.Error()))
+		return err
+	}
+
+	err = s.revokeOtherSessions(ctx, current)
+	if err != nil {
+		log.Error("failed to revoke other sessions", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("totp enabled")
+	return nil
+}
+
+// DisableTOTP turns two-factor authentication off using a code or a recovery code.
+// Users of roles requiring 2FA can't disable it.
+func (s *AuthService) DisableTOTP(ctx context.Context, accessToken, code string) error {
+	const op = "AuthService.DisableTOTP"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("totp disable attempt")
+
+	current, err := s.activeAccessToken(ctx, log, accessToken)
+	if err != nil {
+		return err
+	}
+
+	log = log.With(slog.Int("user_id", current.UserID))
+
+	user, err := s.userRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return ErrAccountNotFound
+	}
+
+	if s.totpRequired(user.Role) {
+		log.Info("totp is required for role", slog.String("role", user.Role))
+		return ErrTOTPRequired
+	}
+
+	subject := totpSubject(user.ID)
+	err = s.checkLockout(ctx, subject)
+	if err != nil {
+		log.Warn("totp locked out", slog.String("error", err.Error()))
+		return err
+	}
+
+	t, err := s.totpRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		log.Info("failed to get totp", slog.String("error", err.Error()))
+		return err
+	}
+
+	// An unconfirmed enrollment is dropped without a code
+	if t.Enabled() {
+		ok, err := s.verifyTOTP(ctx, t, code, true)
+		if err != nil {
+			log.Error("failed to verify totp", slog.String("error", err.Error()))
+			return err
+		}
+		if !ok {
+			log.Info("invalid totp code")
+			s.registerFailure(ctx, log, subject)
+			return ErrInvalidTOTPCode
+		}
+
+		s.resetAttempts(ctx, log, subject)
+	}
+
+	err = s.totpRepo.Delete(ctx, user.ID)
+	if err != nil {
+		log.Error("failed to delete totp", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("totp disabled")
+	return nil
+}
+
+// LoginVerifyTOTP completes a login challenge with a code or a recovery code
+func (s *AuthService) LoginVerifyTOTP(ctx context.Context, challengeID, code string) (*entity.LoginResponse, error) {
+	const op = "AuthService.LoginVerifyTOTP"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("second factor attempt")
+
+	key := loginChallengeKey(challengeID)
+	var challenge entity.LoginChallenge
+	err := s.authCodes.Get(ctx, key, &challenge)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCacheNotFound):
+			log.Info("login challenge not found")
+			return nil, ErrChallengeNotFound
+		default:
+			log.Error("get from cache error", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+
+	log = log.With(slog.Int("user_id", challenge.UserID))
+
+	subject := totpSubject(challenge.UserID)
+	err = s.checkLockout(ctx, subject)
+	if err != nil {
+		log.Warn("totp locked out", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	t, err := s.totpRepo.GetByUserID(ctx, challenge.UserID)
+	if err != nil {
+		log.Info("failed to get totp", slog.String("error", err.Error()))
+		return nil, ErrChallengeNotFound
+	}
+
+	ok, err := s.verifyTOTP(ctx, t, code, true)
+	if err != nil {
+		log.Error("failed to verify totp", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if !ok {
+		log.Info("invalid totp code")
+		s.registerFailure(ctx, log, subject)
+		return nil, ErrInvalidTOTPCode
+	}
+
+	s.resetAttempts(ctx, log, subject)
+
+	// Challenges are single use, a concurrent attempt may have completed it already
+	deleted, err := s.authCodes.Del(ctx, key)
+	if err != nil {
+		log.Error("failed to delete login challenge", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if deleted == 0 {
+		log.Info("login challenge already used")
+		return nil, ErrChallengeNotFound
+	}
+
+	user, err := s.userRepo.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, ErrAccountNotFound
+	}
+
+	err = accountStatusError(user.Status)
+	if err != nil {
+		log.Info("account is not active", slog.String("status", user.Status))
+		return nil, err
+	}
+
+	resp, err := s.startSession(ctx, user, challenge.Client)
+	if err != nil {
+		log.Error("failed to start session", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Info("login successful")
+	return resp, nil
+}
+
+// createLoginChallenge stores a pending second factor check and returns its ID
+func (s *AuthService) createLoginChallenge(ctx context.Context, userID int, client entity.ClientInfo) (string, error) {
+	challengeID, err := s.generateFamilyID()
+	if err != nil {
+		return "", err
+	}
+
+	challenge := entity.LoginChallenge{UserID: userID, Client: client}
+	err = s.authCodes.Set(ctx, loginChallengeKey(challengeID), challenge, s.totpPolicy.ChallengeTTL)
+	if err != nil {
+		return "", err
+	}
+
+	return challengeID, nil
+}
+
+// verifyTOTP checks a code of the authenticator app, optionally accepting a recovery code.
+// Every code is accepted once, recovery codes are consumed.
+// Recovery codes are hashed, they still work when the secret can't be decrypted.
+func (s *AuthService) verifyTOTP(ctx context.Context, t *entity.TOTP, code string, allowRecovery bool) (bool, error) {
+	secret, err := s.secretBox.Decrypt(t.Secret)
+	if err != nil {
+		s.log.Error("failed to decrypt totp secret", slog.Int("user_id", t.UserID), slog.String("error", err.Error()))
+	} else if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		// The counter outlives the accepted skew window
+		used, err := s.authCodes.Incr(ctx, totpUsedKey(t.UserID, step), 3*totp.Period)
+		if err != nil {
+			return false, err
+		}
+		return used == 1, nil
+	}
+
+	if !allowRecovery {
+		return false, nil
+	}
+
+	return s.totpRepo.UseRecoveryCode(ctx, t.UserID, hashRecoveryCode(code))
+}
+
+// totpRequired reports whether the role must use two-factor authentication
+func (s *AuthService) totpRequired(role string) bool {
+	return slices.Contains(s.totpPolicy.RequiredRoles, role)
+}
+
+// totpSatisfied reports whether the user meets the 2FA policy of their role
+func (s *AuthService) totpSatisfied(ctx context.Context, userID int) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	pending, err := s.totpPending(ctx, user)
+	return !pending, err
+}
+
+// totpPending reports whether the role of the user requires 2FA and it is not enabled yet
+func (s *AuthService) totpPending(ctx context.Context, user *entity.User) (bool, error) {
+	if !s.totpRequired(user.Role) {
+		return false, nil
+	}
+
+	t, err := s.totpRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, ErrTOTPNotEnrolled) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return !t.Enabled(), nil
+}
+
+// generateRecoveryCodes returns recovery codes in the form shown to the user and their hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, _recoveryCodeCount)
+	hashes := make([]string, 0, _recoveryCodeCount)
+
+	buf := make([]byte, _recoveryCodeBytes)
+	for range _recoveryCodeCount {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(buf))
+		code := raw[:4] + "-" + raw[4:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode normalizes a recovery code and hashes it
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func totpSubject(userID int) string {
+	return "totp:user:" + strconv.Itoa(userID)
+}
+
+func totpUsedKey(userID int, step int64) string {
+	return "totp_used:" + strconv.Itoa(userID) + ":" + strconv.FormatInt(step, 10)
+}
+
+func loginChallengeKey(challengeID string) string {
+	return "login_challenge:" + challengeID
+}
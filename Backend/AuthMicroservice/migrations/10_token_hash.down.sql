@@ -0,0 +1,8 @@
+-- Digests cannot be reversed, user sessions are dropped and service tokens must be reissued
+DELETE FROM refresh_token;
+DELETE FROM token;
+DELETE FROM service_token;
+
+ALTER TABLE service_token RENAME COLUMN token_hash TO token;
+ALTER TABLE refresh_token RENAME COLUMN token_hash TO token;
+ALTER TABLE token RENAME COLUMN access_token_hash TO access_token;
@@ -0,0 +1,8 @@
+-- Tokens are stored as hex encoded SHA-256 digests
+ALTER TABLE token RENAME COLUMN access_token TO access_token_hash;
+ALTER TABLE refresh_token RENAME COLUMN token TO token_hash;
+ALTER TABLE service_token RENAME COLUMN token TO token_hash;
+
+UPDATE token SET access_token_hash = encode(sha256(convert_to(access_token_hash, 'UTF8')), 'hex');
+UPDATE refresh_token SET token_hash = encode(sha256(convert_to(token_hash, 'UTF8')), 'hex');
+UPDATE service_token SET token_hash = encode(sha256(convert_to(token_hash, 'UTF8')), 'hex');
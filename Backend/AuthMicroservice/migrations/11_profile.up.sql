@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS profile(
+    user_id INT PRIMARY KEY REFERENCES account(id) ON DELETE CASCADE,
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    avatar_url TEXT NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    locale VARCHAR(35) NOT NULL DEFAULT '',
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
@@ -0,0 +1 @@
+DELETE FROM permission WHERE title = 'media:manage';
@@ -0,0 +1,9 @@
+INSERT INTO permission(title, description, created_at, updated_at) VALUES
+    ('media:manage', 'Read and delete media of any user', NOW(), NOW())
+ON CONFLICT (title) DO NOTHING;
+
+INSERT INTO role_permission(role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM role r, permission p
+WHERE r.title = 'admin' AND p.title = 'media:manage'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+ALTER TABLE token ADD COLUMN IF NOT EXISTS totp_pending BOOLEAN NOT NULL DEFAULT FALSE;
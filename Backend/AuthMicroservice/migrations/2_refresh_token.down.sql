@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS token_family_id_idx;
+ALTER TABLE token DROP COLUMN IF EXISTS family_id;
+DROP TABLE IF EXISTS refresh_token;
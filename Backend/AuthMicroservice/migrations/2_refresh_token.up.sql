@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS refresh_token(
+    id INT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id INT REFERENCES account(id) ON DELETE CASCADE,
+    family_id VARCHAR(64) NOT NULL,
+    token VARCHAR(250) UNIQUE NOT NULL,
+    rotated_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS refresh_token_family_id_idx ON refresh_token(family_id);
+
+ALTER TABLE token ADD COLUMN IF NOT EXISTS family_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS token_family_id_idx ON token(family_id);
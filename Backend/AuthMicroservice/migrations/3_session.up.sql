@@ -0,0 +1,7 @@
+ALTER TABLE token ADD COLUMN IF NOT EXISTS user_agent VARCHAR(512);
+ALTER TABLE token ADD COLUMN IF NOT EXISTS ip VARCHAR(64);
+ALTER TABLE token ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
+
+UPDATE token SET last_used_at = updated_at WHERE last_used_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS token_user_id_idx ON token(user_id);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS permission(
+    id INT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    title VARCHAR(250) UNIQUE NOT NULL,
+    description VARCHAR(512),
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permission(
+    role_id INT REFERENCES role(id) ON DELETE CASCADE,
+    permission_id INT REFERENCES permission(id) ON DELETE CASCADE,
+    created_at TIMESTAMP,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO permission(title, description, created_at, updated_at) VALUES
+    ('roles:assign', 'Assign roles to users', NOW(), NOW()),
+    ('roles:manage', 'Manage roles and permissions', NOW(), NOW()),
+    ('service_tokens:generate', 'Generate service tokens', NOW(), NOW())
+ON CONFLICT (title) DO NOTHING;
+
+INSERT INTO role_permission(role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM role r, permission p
+WHERE r.title = 'admin'
+ON CONFLICT DO NOTHING;
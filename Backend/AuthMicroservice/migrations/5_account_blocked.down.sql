@@ -0,0 +1,4 @@
+DELETE FROM permission WHERE title = 'users:manage';
+
+DROP INDEX IF EXISTS account_created_at_idx;
+ALTER TABLE account DROP COLUMN IF EXISTS blocked;
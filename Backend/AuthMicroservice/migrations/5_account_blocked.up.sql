@@ -0,0 +1,13 @@
+ALTER TABLE account ADD COLUMN IF NOT EXISTS blocked BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS account_created_at_idx ON account(created_at);
+
+INSERT INTO permission(title, description, created_at, updated_at) VALUES
+    ('users:manage', 'List, block and delete user accounts', NOW(), NOW())
+ON CONFLICT (title) DO NOTHING;
+
+INSERT INTO role_permission(role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM role r, permission p
+WHERE r.title = 'admin' AND p.title = 'users:manage'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+ALTER TABLE account ADD COLUMN IF NOT EXISTS blocked BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE account SET blocked = TRUE WHERE status IN ('blocked', 'deleted');
+
+ALTER TABLE account DROP CONSTRAINT IF EXISTS account_status_check;
+ALTER TABLE account DROP COLUMN IF EXISTS status;
@@ -0,0 +1,8 @@
+ALTER TABLE account ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active';
+
+UPDATE account SET status = 'blocked' WHERE blocked;
+
+ALTER TABLE account ADD CONSTRAINT account_status_check
+    CHECK (status IN ('pending', 'active', 'blocked', 'deleted'));
+
+ALTER TABLE account DROP COLUMN IF EXISTS blocked;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS account_totp(
+    user_id INT PRIMARY KEY REFERENCES account(id) ON DELETE CASCADE,
+    secret BYTEA NOT NULL,
+    recovery_codes TEXT[] NOT NULL DEFAULT '{}',
+    confirmed_at TIMESTAMP,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
@@ -0,0 +1 @@
+ALTER TABLE telegram_connection ALTER COLUMN tg_user_id TYPE INT;
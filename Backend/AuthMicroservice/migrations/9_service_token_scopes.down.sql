@@ -0,0 +1,12 @@
+DELETE FROM permission WHERE title = 'service_tokens:manage';
+
+-- Keep only the newest token of every service
+DELETE FROM service_token t
+USING service_token newer
+WHERE t.service_name = newer.service_name AND t.id < newer.id;
+
+DROP INDEX IF EXISTS service_token_service_name_idx;
+ALTER TABLE service_token ADD CONSTRAINT service_token_service_name_key UNIQUE (service_name);
+
+ALTER TABLE service_token DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE service_token DROP COLUMN IF EXISTS scopes;
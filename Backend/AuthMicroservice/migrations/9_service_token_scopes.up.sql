@@ -0,0 +1,19 @@
+ALTER TABLE service_token ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE service_token ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+-- Existing tokens keep access to everything they could call before scopes
+UPDATE service_token SET scopes = ARRAY['auth:generate_code', 'auth:resolve_tg'] WHERE scopes = '{}';
+
+-- A rotated service keeps its old token valid during the overlap window
+ALTER TABLE service_token DROP CONSTRAINT IF EXISTS service_token_service_name_key;
+CREATE INDEX IF NOT EXISTS service_token_service_name_idx ON service_token(service_name);
+
+INSERT INTO permission(title, description, created_at, updated_at) VALUES
+    ('service_tokens:manage', 'List, rotate and revoke service tokens', NOW(), NOW())
+ON CONFLICT (title) DO NOTHING;
+
+INSERT INTO role_permission(role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM role r, permission p
+WHERE r.title = 'admin' AND p.title = 'service_tokens:manage'
+ON CONFLICT DO NOTHING;